@@ -0,0 +1,45 @@
+package main
+
+import (
+	"database/sql"
+
+	"qna-api/internal/model"
+)
+
+// backfillSlugs строит slug существующих вопросов через model.Slugify, чтобы он
+// совпадал со slug, который сервер строит из заголовка (в том числе не латинского)
+func backfillSlugs(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, title FROM questions WHERE slug = ''`)
+	if err != nil {
+		return err
+	}
+	type questionSlug struct {
+		id   int
+		slug string
+	}
+	var slugs []questionSlug
+	for rows.Next() {
+		var id int
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return err
+		}
+		if slug := model.Slugify(title); slug != "" {
+			slugs = append(slugs, questionSlug{id: id, slug: slug})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Обновления выполняются после чтения: соединение транзакции не выполняет
+	// запрос, пока не прочитан результат предыдущего
+	for _, q := range slugs {
+		if _, err := tx.Exec(`UPDATE questions SET slug = $1 WHERE id = $2`, q.slug, q.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillSlugs(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Slug совпадает с model.Slugify: кириллица сохраняется, а не вырезается
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, title FROM questions WHERE slug = ''`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).
+			AddRow(1, "Как использовать Go?").
+			AddRow(2, "Hello, World!").
+			AddRow(3, "???"))
+	mock.ExpectExec(`UPDATE questions SET slug = $1 WHERE id = $2`).
+		WithArgs("как-использовать-go", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE questions SET slug = $1 WHERE id = $2`).
+		WithArgs("hello-world", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, backfillSlugs(tx))
+	require.NoError(t, tx.Commit())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
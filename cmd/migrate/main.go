@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"qna-api/internal/config"
+	"qna-api/internal/repository"
 
 	_ "github.com/lib/pq"
 )
@@ -42,13 +43,61 @@ func main() {
 		log.Fatal("Failed to ping DB:", err)
 	}
 
-	// Выполняем миграции
-	migrations := []string{
+	// Таблица версий создается до миграций: по ней пропускаются уже примененные
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+            version BIGINT PRIMARY KEY,
+            applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`); err != nil {
+		log.Fatal("Failed to create schema_migrations:", err)
+	}
+
+	for _, m := range migrations {
+		applied, err := apply(db, m)
+		if err != nil {
+			log.Fatalf("Migration %d failed: %v", m.version, err)
+		}
+		if applied {
+			fmt.Printf("Migration %d applied successfully\n", m.version)
+		}
+	}
+
+	// Столбцы полнотекстового поиска строятся для языка из SEARCH_LANGUAGE
+	// и пересоздаются при каждом запуске, поэтому версия для них не записывается
+	searchSchema, err := repository.SearchSchema(config.Load().SearchLanguage)
+	if err != nil {
+		log.Fatal("Invalid search configuration:", err)
+	}
+	for _, statement := range searchSchema {
+		if _, err := db.Exec(statement); err != nil {
+			log.Fatal("Search schema migration failed:", err)
+		}
+	}
+
+	fmt.Println("All migrations completed successfully for database:", dbName)
+}
+
+// migration - версия схемы, соответствующая файлу migrations/NNN_*.sql, если он есть;
+// выражения идемпотентны, чтобы повторный запуск на базе без schema_migrations
+// (созданной до появления версий) не падал
+type migration struct {
+	version    int64
+	statements []string
+	run        func(tx *sql.Tx) error // шаг на Go после выражений, если есть
+}
+
+// migrations - версии схемы по порядку. Столбцы поиска из версии 9 применяются
+// отдельно через repository.SearchSchema: они зависят от заголовка из версии 11
+var migrations = []migration{
+	// 001_create_questions_table.sql
+	{version: 1, statements: []string{
 		`CREATE TABLE IF NOT EXISTS questions (
             id SERIAL PRIMARY KEY,
             text TEXT NOT NULL,
             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
         )`,
+	}},
+	// 002_create_answer_table.sql
+	{version: 2, statements: []string{
 		`CREATE TABLE IF NOT EXISTS answers (
             id SERIAL PRIMARY KEY,
             question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
@@ -58,14 +107,194 @@ func main() {
         )`,
 		`CREATE INDEX IF NOT EXISTS idx_answers_question_id ON answers(question_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_answers_user_id ON answers(user_id)`,
+	}},
+	// 003_add_updated_at_and_revisions.sql
+	{version: 3, statements: []string{
+		// Существующие записи считаются неизмененными с момента создания; заполняется
+		// только вместе с добавлением столбца, чтобы не затереть время правок
+		`DO $$ BEGIN
+            IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                WHERE table_name = 'questions' AND column_name = 'updated_at') THEN
+                ALTER TABLE questions ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+                UPDATE questions SET updated_at = created_at;
+            END IF;
+        END $$`,
+		`DO $$ BEGIN
+            IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                WHERE table_name = 'answers' AND column_name = 'updated_at') THEN
+                ALTER TABLE answers ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+                UPDATE answers SET updated_at = created_at;
+            END IF;
+        END $$`,
+		`CREATE TABLE IF NOT EXISTS revisions (
+            id SERIAL PRIMARY KEY,
+            entity_type VARCHAR(16) NOT NULL,
+            entity_id INTEGER NOT NULL,
+            editor_id VARCHAR(36) NOT NULL,
+            changes JSONB NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_revisions_entity ON revisions(entity_type, entity_id)`,
+		`CREATE OR REPLACE RULE revisions_no_update AS ON UPDATE TO revisions DO INSTEAD NOTHING`,
+		`CREATE OR REPLACE RULE revisions_no_delete AS ON DELETE TO revisions DO INSTEAD NOTHING`,
+	}},
+	// 004_add_soft_delete.sql
+	{version: 4, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE answers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE`,
+		`CREATE INDEX IF NOT EXISTS idx_questions_deleted_at ON questions(deleted_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_answers_deleted_at ON answers(deleted_at)`,
+	}},
+	// 005_add_locks_and_user_roles.sql
+	{version: 5, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE answers ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE`,
+		`CREATE TABLE IF NOT EXISTS user_roles (
+            user_id VARCHAR(36) PRIMARY KEY,
+            role VARCHAR(16) NOT NULL CHECK (role IN ('user', 'moderator', 'admin')),
+            granted_by VARCHAR(36) NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+	}},
+	// 006_add_question_user_id.sql
+	{version: 6, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS user_id VARCHAR(36) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_questions_user_id ON questions(user_id)`,
+	}},
+	// 007_add_votes.sql
+	{version: 7, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE answers ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_questions_score ON questions(score DESC, created_at DESC, id DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_answers_question_score ON answers(question_id, score DESC, created_at DESC, id DESC)`,
+		`CREATE TABLE IF NOT EXISTS votes (
+            id SERIAL PRIMARY KEY,
+            entity_type VARCHAR(16) NOT NULL,
+            entity_id INTEGER NOT NULL,
+            user_id VARCHAR(36) NOT NULL,
+            value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_votes_entity_user ON votes(entity_type, entity_id, user_id)`,
+	}},
+	// 008_add_accepted_answer.sql
+	{version: 8, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS accepted_answer_id INTEGER REFERENCES answers(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_questions_accepted_answer_id ON questions(accepted_answer_id)`,
+	}},
+	// 010_add_tags.sql
+	{version: 10, statements: []string{
+		`CREATE TABLE IF NOT EXISTS tags (
+            id SERIAL PRIMARY KEY,
+            name VARCHAR(32) NOT NULL UNIQUE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE TABLE IF NOT EXISTS question_tags (
+            question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+            tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+            PRIMARY KEY (question_id, tag_id)
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_question_tags_tag_id ON question_tags(tag_id)`,
+		`CREATE TABLE IF NOT EXISTS tag_synonyms (
+            name VARCHAR(32) PRIMARY KEY,
+            tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_synonyms_tag_id ON tag_synonyms(tag_id)`,
+	}},
+	// Заголовок и slug вопроса. SQL-файла у версии нет: slug строится через
+	// model.Slugify, а lower и [:alnum:] в Postgres зависят от локали базы
+	{version: 11, statements: []string{
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS title VARCHAR(150) NOT NULL DEFAULT ''`,
+		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS slug VARCHAR(80) NOT NULL DEFAULT ''`,
+		`UPDATE questions SET title = left(btrim(split_part(btrim(text, E' \t\r\n'), E'\n', 1), E' \t\r'), 150)
+        WHERE title = ''`,
+	}, run: backfillSlugs},
+	// 012_add_comments.sql
+	{version: 12, statements: []string{
+		`CREATE TABLE IF NOT EXISTS comments (
+            id SERIAL PRIMARY KEY,
+            entity_type VARCHAR(16) NOT NULL,
+            entity_id INTEGER NOT NULL,
+            user_id VARCHAR(36) NOT NULL,
+            text TEXT NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_entity ON comments(entity_type, entity_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments(user_id)`,
+	}},
+	// 013_add_webhooks.sql
+	{version: 13, statements: []string{
+		`CREATE TABLE IF NOT EXISTS webhooks (
+            id SERIAL PRIMARY KEY,
+            url VARCHAR(2048) NOT NULL,
+            events JSONB NOT NULL DEFAULT '[]',
+            secret VARCHAR(255) NOT NULL,
+            created_by VARCHAR(36) NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+            id SERIAL PRIMARY KEY,
+            webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+            event_type VARCHAR(32) NOT NULL,
+            payload JSONB NOT NULL,
+            status VARCHAR(16) NOT NULL DEFAULT 'pending',
+            attempts INTEGER NOT NULL DEFAULT 0,
+            next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            response_status INTEGER NOT NULL DEFAULT 0,
+            last_error TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            delivered_at TIMESTAMP WITH TIME ZONE
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at)`,
+	}},
+	// 014_add_events_outbox.sql
+	{version: 14, statements: []string{
+		`CREATE TABLE IF NOT EXISTS events (
+            id BIGSERIAL PRIMARY KEY,
+            aggregate_type VARCHAR(32) NOT NULL,
+            aggregate_id INTEGER NOT NULL,
+            type VARCHAR(32) NOT NULL,
+            payload JSONB NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            published_at TIMESTAMP WITH TIME ZONE
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_events_aggregate ON events(aggregate_type, aggregate_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_events_unpublished ON events(published_at) WHERE published_at IS NULL`,
+	}},
+}
+
+// apply выполняет миграцию в транзакции вместе с записью версии в schema_migrations;
+// уже примененная версия пропускается
+func apply(db *sql.DB, m migration) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
 	}
+	defer tx.Rollback()
 
-	for i, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			log.Fatalf("Migration %d failed: %v", i+1, err)
-		}
-		fmt.Printf("Migration %d applied successfully\n", i+1)
+	var applied bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`,
+		m.version).Scan(&applied); err != nil {
+		return false, err
+	}
+	if applied {
+		return false, nil
 	}
 
-	fmt.Println("All migrations completed successfully for database:", dbName)
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return false, err
+		}
+	}
+	if m.run != nil {
+		if err := m.run(tx); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
 }
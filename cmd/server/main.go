@@ -1,42 +1,213 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/nats-io/nats.go"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 
+	"qna-api/internal/auth"
 	"qna-api/internal/config"
+	"qna-api/internal/events"
 	"qna-api/internal/handler"
+	"qna-api/internal/health"
+	"qna-api/internal/logging"
+	"qna-api/internal/metrics"
 	"qna-api/internal/model"
+	"qna-api/internal/outbox"
 	"qna-api/internal/repository"
+	"qna-api/internal/server"
 	"qna-api/internal/service"
+	"qna-api/internal/tracing"
+	"qna-api/internal/webhook"
 )
 
 func main() {
 	cfg := config.Load()
 
+	// Structured JSON logs; records with a request context carry request and trace IDs
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		fatal("Failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
+	// Tracing: W3C traceparent propagation and, if configured, span export
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("Failed to configure tracing", err)
+	}
+
 	// Initialize database
-	db, err := gorm.Open(postgres.Open(cfg.GetDBConnectionString()), &gorm.Config{})
+	// GORM reports failed and slow queries through the JSON logger
+	db, err := gorm.Open(postgres.Open(cfg.GetDBConnectionString()), &gorm.Config{
+		Logger: gormlogger.New(logging.Printer{Logger: logger, Level: slog.LevelWarn}, gormlogger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  gormlogger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to get database pool", err)
+	}
+
+	// Prometheus metrics: query durations via a GORM plugin and connection pool stats
+	appMetrics := metrics.New()
+	if err := db.Use(appMetrics.GORMPlugin()); err != nil {
+		fatal("Failed to instrument database", err)
+	}
+	appMetrics.RegisterDBStats(sqlDB, cfg.DBName)
+	if err := db.Use(tracing.GORMPlugin{}); err != nil {
+		fatal("Failed to instrument database", err)
 	}
 
 	// Auto migrate models
-	if err := db.AutoMigrate(&model.Question{}, &model.Answer{}); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	if err := db.AutoMigrate(&model.Question{}, &model.Answer{}, &model.Revision{}, &model.UserRole{}, &model.Vote{}, &model.Tag{}, &model.TagSynonym{}, &model.Comment{}, &model.Webhook{}, &model.WebhookDelivery{}, &model.OutboxEvent{}); err != nil {
+		fatal("Failed to migrate database", err)
+	}
+
+	// Full-text search columns depend on the configured language
+	searchSchema, err := repository.SearchSchema(cfg.SearchLanguage)
+	if err != nil {
+		fatal("Failed to configure search", err)
+	}
+	for _, statement := range searchSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			fatal("Failed to migrate search columns", err)
+		}
 	}
 
 	// Initialize layers
-	repo := repository.NewRepository(db) // Возвращает RepositoryInterface
-	svc := service.NewService(repo)      // Принимает RepositoryInterface
-	h := handler.NewHandler(svc)
+	// NewRepository возвращает RepositoryInterface, NewService принимает его
+	repo := repository.NewRepository(db, repository.WithSearchLanguage(cfg.SearchLanguage))
+	broker := events.NewBroker(cfg.EventReplaySize)
+	svc := service.NewTracedService(service.NewService(repo, service.WithMetrics(appMetrics)))
+	srv := server.New(cfg)
+	h := handler.NewHandler(svc,
+		handler.WithEvents(broker),
+		handler.WithRequestTimeout(cfg.RequestTimeout),
+		handler.WithReadiness(srv.Ready),
+		handler.WithMetrics(appMetrics.Handler()),
+		handler.WithHealth(health.NewChecker(cfg.HealthCheckTimeout,
+			health.Database(sqlDB), health.Migrations(sqlDB))))
+
+	// SIGINT/SIGTERM stop the server and background jobs
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var jobs sync.WaitGroup
+	runJob := func(job func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job(ctx)
+		}()
+	}
+
+	// Every instance tails the outbox into its own broker, so SSE/WebSocket clients get events
+	// whichever instance they are connected to
+	eventTail := outbox.NewTail(repo, broker)
+	if err := eventTail.Start(ctx, cfg.EventReplaySize); err != nil {
+		fatal("Failed to read events", err)
+	}
+	runJob(func(ctx context.Context) { eventTail.Run(ctx, cfg.EventDispatchInterval) })
+
+	// External sinks are fed by a single dispatcher across instances, which marks events published
+	var sinks []outbox.Sink
+	if cfg.EventLogFile != "" {
+		fileSink, err := outbox.NewFileSink(cfg.EventLogFile)
+		if err != nil {
+			fatal("Failed to open event log", err)
+		}
+		defer fileSink.Close()
+		sinks = append(sinks, fileSink)
+	}
+	if cfg.EventNATSURL != "" {
+		conn, err := nats.Connect(cfg.EventNATSURL, nats.Name("qna-api"))
+		if err != nil {
+			fatal("Failed to connect to NATS", err)
+		}
+		defer conn.Close()
+		sinks = append(sinks, outbox.NewNATSSink(conn, cfg.EventNATSSubject))
+	}
+	if len(cfg.EventKafkaBrokers) > 0 {
+		producer := outbox.NewKafkaWriter(cfg.EventKafkaBrokers...)
+		defer producer.Close()
+		sinks = append(sinks, outbox.NewKafkaSink(producer, cfg.EventKafkaTopic))
+	}
+	if len(sinks) > 0 {
+		eventDispatcher := outbox.NewDispatcher(repo, sinks...)
+		runJob(func(ctx context.Context) { eventDispatcher.Run(ctx, cfg.EventDispatchInterval) })
+	}
+
+	// Background purge of soft-deleted records
+	runJob(func(ctx context.Context) {
+		service.RunPurgeJob(ctx, svc, cfg.PurgeInterval, cfg.SoftDeleteRetention)
+	})
+
+	// Background delivery of outgoing webhooks from the outbox
+	webhookDispatcher := webhook.NewDispatcher(repo, &http.Client{Timeout: cfg.WebhookTimeout})
+	runJob(func(ctx context.Context) { webhookDispatcher.Run(ctx, cfg.WebhookDispatchInterval) })
+
+	// Authentication
+	verifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		fatal("Failed to configure authentication", err)
+	}
+	if !verifier.Enabled() {
+		slog.Warn("No JWT keys configured, all requests are anonymous")
+	}
 
 	// Setup routes
 	router := h.InitRoutes()
+	router.Use(logging.RequestID())
+	// Tracing wraps the access log so that its entries carry trace_id and span_id
+	router.Use(tracing.Middleware())
+	router.Use(logging.AccessLog(logger, "/livez", "/readyz", "/health", "/metrics"))
+	router.Use(appMetrics.Middleware())
+	router.Use(auth.Middleware(verifier, svc))
+
+	// SSE and WebSocket streams are not waited for on shutdown: close them so clients reconnect elsewhere
+	srv.OnShutdown(broker.Close)
+
+	// Serve until a signal, then drain in-flight requests
+	slog.Info("Server starting", "port", cfg.ServerPort)
+	serveErr := srv.Run(ctx, router)
+	if serveErr != nil {
+		slog.Error("Server stopped with error", "error", serveErr)
+	}
+
+	// Background jobs finish their current pass before the pool is closed
+	stop()
+	jobs.Wait()
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("Failed to close database pool", "error", err)
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	cancel()
+	slog.Info("Server stopped")
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
 
-	// Start server
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	log.Fatal(http.ListenAndServe(":"+cfg.ServerPort, router))
+// fatal logs a startup error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
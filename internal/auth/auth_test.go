@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"qna-api/internal/config"
+	"qna-api/internal/logging"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Идентификаторы пользователей в токенах - UUID
+const (
+	testSubject      = "3f1e9c2a-5b7d-4e8f-a1c3-9d2b6e4f7a10"
+	adminSubject     = "8c2d4e6f-1a3b-4c5d-9e7f-0a1b2c3d4e5f"
+	moderatorSubject = "b7a6c5d4-e3f2-4a1b-8c9d-0e1f2a3b4c5d"
+)
+
+func signHS256(t *testing.T, secret string, claims jwt.RegisteredClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.RegisteredClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(subject string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func jwksJSON(key *rsa.PublicKey, kid string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	return data
+}
+
+func TestVerify_HS256(t *testing.T) {
+	v, err := NewVerifier(&config.Config{JWTSecret: "secret"})
+	require.NoError(t, err)
+
+	identity, err := v.Verify(signHS256(t, "secret", validClaims(testSubject)))
+	assert.NoError(t, err)
+	assert.Equal(t, testSubject, identity.Subject)
+
+	// Чужой секрет
+	_, err = v.Verify(signHS256(t, "other", validClaims(testSubject)))
+	assert.Error(t, err)
+
+	// Истекший токен
+	expired := validClaims(testSubject)
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	_, err = v.Verify(signHS256(t, "secret", expired))
+	assert.Error(t, err)
+
+	// Токен без срока действия
+	_, err = v.Verify(signHS256(t, "secret", jwt.RegisteredClaims{Subject: testSubject}))
+	assert.Error(t, err)
+}
+
+func TestVerify_RS256FromJWKSFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(&key.PublicKey, "key-1"), 0o600))
+
+	v, err := NewVerifier(&config.Config{JWKSFile: path})
+	require.NoError(t, err)
+
+	identity, err := v.Verify(signRS256(t, key, "key-1", validClaims(testSubject)))
+	assert.NoError(t, err)
+	assert.Equal(t, testSubject, identity.Subject)
+
+	// HS256 не принимается, если секрет не настроен
+	_, err = v.Verify(signHS256(t, "secret", validClaims(testSubject)))
+	assert.Error(t, err)
+}
+
+func TestVerify_RS256FromJWKSURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwksJSON(&key.PublicKey, "key-1"))
+	}))
+	defer server.Close()
+
+	v, err := NewVerifier(&config.Config{JWKSURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = v.Verify(signRS256(t, key, "key-1", validClaims(testSubject)))
+	assert.NoError(t, err)
+
+	_, err = v.Verify(signRS256(t, key, "unknown", validClaims(testSubject)))
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	v, err := NewVerifier(&config.Config{JWTSecret: "secret"})
+	require.NoError(t, err)
+
+	var subject string
+	handler := Middleware(v, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := FromContext(r.Context())
+		subject = identity.Subject
+	}))
+
+	// Анонимный запрос проходит без пользователя
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, subject)
+
+	// Действительный токен
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signHS256(t, "secret", validClaims(testSubject)))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, testSubject, subject)
+
+	// Недействительный токен
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer garbage")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// Подпись верна, но sub не UUID: токен отклоняется, а не запросы на создание
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signHS256(t, "secret", validClaims("user-123")))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, `Bearer error="invalid_token"`, rr.Header().Get("WWW-Authenticate"))
+}
+
+// stubRoles - хранилище ролей для тестов
+type stubRoles map[string]string
+
+func (s stubRoles) GetUserRole(ctx context.Context, userID string) (string, error) {
+	return s[userID], nil
+}
+
+func TestVerify_RoleClaim(t *testing.T) {
+	v, err := NewVerifier(&config.Config{JWTSecret: "secret"})
+	require.NoError(t, err)
+
+	sign := func(role string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+			RegisteredClaims: validClaims(testSubject),
+			Role:             role,
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return token
+	}
+
+	identity, err := v.Verify(sign("moderator"))
+	require.NoError(t, err)
+	assert.Equal(t, RoleModerator, identity.Role)
+
+	// Без роли пользователь считается обычным
+	identity, err = v.Verify(sign(""))
+	require.NoError(t, err)
+	assert.Equal(t, RoleUser, identity.Role)
+
+	_, err = v.Verify(sign("superuser"))
+	assert.Error(t, err)
+}
+
+func TestMiddleware_Roles(t *testing.T) {
+	v, err := NewVerifier(&config.Config{AuthTrustedHeaders: true})
+	require.NoError(t, err)
+
+	var identity Identity
+	handler := Middleware(v, stubRoles{adminSubject: "admin"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = FromContext(r.Context())
+	}))
+
+	// Роль из доверенного заголовка
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User-ID", moderatorSubject)
+	req.Header.Set("X-User-Role", "moderator")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, Identity{Subject: moderatorSubject, Role: RoleModerator}, identity)
+
+	// Назначенная администратором роль важнее заголовка
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User-ID", adminSubject)
+	req.Header.Set("X-User-Role", "user")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, RoleAdmin, identity.Role)
+
+	// Идентификатор пользователя не UUID
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User-ID", "user-2")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// Неизвестная роль
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User-ID", moderatorSubject)
+	req.Header.Set("X-User-Role", "root")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestMiddleware_AnnotatesAccessLog(t *testing.T) {
+	v, err := NewVerifier(&config.Config{AuthTrustedHeaders: true})
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info")
+	require.NoError(t, err)
+
+	// Журнал запросов оборачивает аутентификацию и получает пользователя от нее
+	router := mux.NewRouter()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	router.Use(logging.AccessLog(logger))
+	router.Use(Middleware(v, stubRoles{adminSubject: "admin"}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User-ID", adminSubject)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, adminSubject, entry["user"])
+	assert.Equal(t, "admin", entry["role"])
+}
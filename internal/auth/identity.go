@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Role - роль пользователя; роли упорядочены по возрастанию прав
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole проверяет, что строка - известная роль
+func ParseRole(s string) (Role, bool) {
+	role := Role(s)
+	_, ok := roleRank[role]
+	return role, ok
+}
+
+// AtLeast сообщает, что роль не ниже min
+func (r Role) AtLeast(min Role) bool {
+	return roleRank[r] >= roleRank[min]
+}
+
+// Identity - аутентифицированный пользователь, выполняющий запрос
+type Identity struct {
+	Subject string
+	Role    Role
+}
+
+// ValidSubject проверяет, что идентификатор пользователя - UUID в каноническом виде:
+// он сохраняется как автор записей в столбцах VARCHAR(36)
+func ValidSubject(subject string) bool {
+	if len(subject) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(subject)
+	return err == nil
+}
+
+type contextKey struct{}
+
+// WithIdentity кладет пользователя в контекст запроса
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext возвращает пользователя из контекста запроса
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"qna-api/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier проверяет подпись и срок действия bearer-токенов
+type Verifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	keySet     *keySet
+	parser     *jwt.Parser
+
+	// Доверять заголовкам X-User-ID/X-User-Role от шлюза перед сервисом
+	trustHeaders bool
+}
+
+// claims - зарегистрированные claims и роль пользователя
+type claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
+// NewVerifier собирает проверку токенов из настроек.
+// Без настроенных ключей любой токен отклоняется.
+func NewVerifier(cfg *config.Config) (*Verifier, error) {
+	v := &Verifier{trustHeaders: cfg.AuthTrustedHeaders}
+
+	if cfg.JWTSecret != "" {
+		v.hmacSecret = []byte(cfg.JWTSecret)
+	}
+
+	if cfg.JWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT public key: %w", err)
+		}
+		if v.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			return nil, fmt.Errorf("parse JWT public key: %w", err)
+		}
+	}
+
+	var err error
+	switch {
+	case cfg.JWKSFile != "":
+		v.keySet, err = loadKeySetFile(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		v.keySet, err = loadKeySetURL(cfg.JWKSURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load JWKS: %w", err)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Enabled сообщает, настроен ли хотя бы один ключ проверки
+func (v *Verifier) Enabled() bool {
+	return v.hmacSecret != nil || v.rsaKey != nil || v.keySet != nil || v.trustHeaders
+}
+
+// Verify проверяет токен и возвращает пользователя из claims sub и role.
+// sub должен быть UUID; без claim role пользователь получает роль user.
+func (v *Verifier) Verify(tokenString string) (Identity, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &c, v.keyFunc); err != nil {
+		return Identity{}, err
+	}
+	if c.Subject == "" {
+		return Identity{}, errors.New("token has no subject")
+	}
+	if !ValidSubject(c.Subject) {
+		return Identity{}, fmt.Errorf("token subject %q is not a UUID", c.Subject)
+	}
+
+	role := RoleUser
+	if c.Role != "" {
+		var ok bool
+		if role, ok = ParseRole(c.Role); !ok {
+			return Identity{}, fmt.Errorf("unknown role %q", c.Role)
+		}
+	}
+	return Identity{Subject: c.Subject, Role: role}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.hmacSecret == nil {
+			return nil, errors.New("HMAC tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+	case *jwt.SigningMethodRSA:
+		// Сначала ищем ключ в JWKS, затем используем статический ключ
+		if v.keySet != nil {
+			kid, _ := token.Header["kid"].(string)
+			key, err := v.keySet.key(kid)
+			if err == nil {
+				return key, nil
+			}
+			if v.rsaKey == nil {
+				return nil, err
+			}
+		}
+		if v.rsaKey == nil {
+			return nil, errors.New("RSA tokens are not accepted")
+		}
+		return v.rsaKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+}
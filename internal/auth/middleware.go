@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"qna-api/internal/logging"
+	"qna-api/internal/problem"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	errInvalidHeader = errors.New("Invalid authorization header")
+	errInvalidToken  = errors.New("Invalid token")
+)
+
+// RoleStore - роли, назначенные администраторами; они важнее роли из токена
+type RoleStore interface {
+	GetUserRole(ctx context.Context, userID string) (string, error)
+}
+
+// Middleware проверяет заголовок Authorization и кладет пользователя в контекст.
+// Запросы без заголовка проходят анонимно; недействительный токен - 401.
+func Middleware(v *Verifier, roles RoleStore) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok, err := v.authenticate(r)
+			if err != nil {
+				unauthorized(w, err.Error())
+				return
+			}
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if roles != nil {
+				identity.Role = resolveRole(r.Context(), roles, identity)
+			}
+			logging.Annotate(r.Context(), slog.String("user", identity.Subject), slog.String("role", string(identity.Role)))
+
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// authenticate извлекает пользователя из bearer-токена или доверенных заголовков
+func (v *Verifier) authenticate(r *http.Request) (Identity, bool, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return Identity{}, false, errInvalidHeader
+		}
+		identity, err := v.Verify(token)
+		if err != nil {
+			return Identity{}, false, errInvalidToken
+		}
+		return identity, true, nil
+	}
+
+	if v.trustHeaders {
+		if subject := r.Header.Get("X-User-ID"); subject != "" {
+			if !ValidSubject(subject) {
+				return Identity{}, false, errInvalidHeader
+			}
+			role := RoleUser
+			if raw := r.Header.Get("X-User-Role"); raw != "" {
+				var ok bool
+				if role, ok = ParseRole(raw); !ok {
+					return Identity{}, false, errInvalidHeader
+				}
+			}
+			return Identity{Subject: subject, Role: role}, true, nil
+		}
+	}
+
+	return Identity{}, false, nil
+}
+
+// resolveRole отдает приоритет роли, назначенной администратором
+func resolveRole(ctx context.Context, roles RoleStore, identity Identity) Role {
+	stored, err := roles.GetUserRole(ctx, identity.Subject)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load user role", "user", identity.Subject, "error", err)
+		return identity.Role
+	}
+	if role, ok := ParseRole(stored); ok {
+		return role
+	}
+	return identity.Role
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	problem.Write(w, problem.New(http.StatusUnauthorized, "unauthenticated", message))
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +15,66 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	ServerPort string
+
+	// Уровень JSON-логов: debug, info, warn, error
+	LogLevel string
+
+	// Предельное время обработки одного запроса; по его истечении запросы к базе прерываются (0 - без ограничения)
+	RequestTimeout time.Duration
+
+	// Предельное время одной проверки зависимостей в /readyz и /health
+	HealthCheckTimeout time.Duration
+
+	// Таймауты соединений HTTP-сервера; WriteTimeout должен быть больше RequestTimeout
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+
+	// Остановка: сколько сервер остается неготовым до закрытия приема и сколько ждет текущих запросов
+	ShutdownDrainDelay time.Duration
+	ShutdownTimeout    time.Duration
+
+	// Мягко удаленные записи хранятся SoftDeleteRetention, затем удаляются окончательно
+	SoftDeleteRetention time.Duration
+	PurgeInterval       time.Duration
+
+	// Проверка JWT: HS256-секрет, RS256-ключ в PEM и/или JWKS из файла или по URL
+	JWTSecret        string
+	JWTPublicKeyFile string
+	JWKSFile         string
+	JWKSURL          string
+	JWTIssuer        string
+	JWTAudience      string
+
+	// Принимать пользователя из заголовков X-User-ID/X-User-Role (только за доверенным шлюзом)
+	AuthTrustedHeaders bool
+
+	// Конфигурация текстового поиска PostgreSQL (english, russian, simple, ...)
+	SearchLanguage string
+
+	// Сколько последних событий хранится для возобновления потоков по Last-Event-ID
+	EventReplaySize int
+
+	// Разбор журнала событий: период публикации и файл, куда события дописываются (пусто - не писать)
+	EventDispatchInterval time.Duration
+	EventLogFile          string
+
+	// Внешние приемники событий: NATS (URL сервера и префикс subject) и Kafka
+	// (брокеры через запятую и topic); пустой адрес - приемник выключен
+	EventNATSURL      string
+	EventNATSSubject  string
+	EventKafkaBrokers []string
+	EventKafkaTopic   string
+
+	// Отправка вебхуков: период разбора outbox и таймаут одного запроса
+	WebhookDispatchInterval time.Duration
+	WebhookTimeout          time.Duration
+
+	// Трассировка: экспортер (none, otlp, stdout, file) и файл для экспортера file.
+	// OTLP настраивается стандартными переменными OTEL_EXPORTER_OTLP_*.
+	TracingExporter string
+	TracingFile     string
 }
 
 func Load() *Config {
@@ -22,6 +85,48 @@ func Load() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "password"),
 		DBName:     getEnv("DB_NAME", "qna_db"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
+
+		LogLevel: getEnv("LOG_LEVEL", "info"),
+
+		RequestTimeout:     getDuration("REQUEST_TIMEOUT", 15*time.Second),
+		HealthCheckTimeout: getDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+
+		ServerReadTimeout:       getDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerReadHeaderTimeout: getDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerWriteTimeout:      getDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+		ServerIdleTimeout:       getDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+
+		ShutdownDrainDelay: getDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		ShutdownTimeout:    getDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		SoftDeleteRetention: getDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+		PurgeInterval:       getDuration("PURGE_INTERVAL", time.Hour),
+
+		JWTSecret:        getEnv("JWT_SECRET", ""),
+		JWTPublicKeyFile: getEnv("JWT_PUBLIC_KEY_FILE", ""),
+		JWKSFile:         getEnv("JWKS_FILE", ""),
+		JWKSURL:          getEnv("JWKS_URL", ""),
+		JWTIssuer:        getEnv("JWT_ISSUER", ""),
+		JWTAudience:      getEnv("JWT_AUDIENCE", ""),
+
+		AuthTrustedHeaders: getEnv("AUTH_TRUSTED_HEADERS", "false") == "true",
+
+		SearchLanguage: getEnv("SEARCH_LANGUAGE", "english"),
+
+		EventReplaySize:       getInt("EVENT_REPLAY_SIZE", 1000),
+		EventDispatchInterval: getDuration("EVENT_DISPATCH_INTERVAL", time.Second),
+		EventLogFile:          getEnv("EVENT_LOG_FILE", ""),
+
+		EventNATSURL:      getEnv("EVENT_NATS_URL", ""),
+		EventNATSSubject:  getEnv("EVENT_NATS_SUBJECT", "qna.events"),
+		EventKafkaBrokers: getList("EVENT_KAFKA_BROKERS"),
+		EventKafkaTopic:   getEnv("EVENT_KAFKA_TOPIC", "qna-events"),
+
+		WebhookDispatchInterval: getDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+		WebhookTimeout:          getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		TracingExporter: getEnv("TRACING_EXPORTER", "none"),
+		TracingFile:     getEnv("TRACING_FILE", "traces.jsonl"),
 	}
 }
 
@@ -36,3 +141,32 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getList разбирает список через запятую, пропуская пустые элементы
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
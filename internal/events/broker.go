@@ -0,0 +1,172 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"qna-api/internal/model"
+)
+
+// DefaultReplaySize - сколько последних событий брокер хранит для возобновления подписки
+const DefaultReplaySize = 1000
+
+// subscriberBuffer - размер очереди подписчика; переполнение отключает подписчика
+const subscriberBuffer = 64
+
+// Publisher - получатель доменных событий
+type Publisher interface {
+	Publish(event model.Event)
+}
+
+// Filter отбирает события для подписчика; nil пропускает все события
+type Filter func(event model.Event) bool
+
+// ForQuestion пропускает только события вопроса questionID и его ответов
+func ForQuestion(questionID int) Filter {
+	return func(event model.Event) bool {
+		return event.QuestionID == questionID
+	}
+}
+
+// Broker - внутрипроцессная pub/sub-шина событий с кольцевым буфером для повтора.
+// Publish не блокируется: подписчик, который не успевает читать, отключается.
+type Broker struct {
+	mu          sync.Mutex
+	lastID      uint64        // наибольший ID опубликованного события
+	replay      []model.Event // кольцевой буфер последних событий
+	next        int           // позиция для следующей записи в replay
+	full        bool
+	closed      bool
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker создает брокер, хранящий replaySize последних событий
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Broker{
+		replay:      make([]model.Event, replaySize),
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish сохраняет событие в буфере и рассылает подписчикам. ID события - ID записи
+// журнала событий, одинаковый на всех экземплярах и после перезапуска; брокер только
+// запоминает наибольший ID и отбрасывает события не новее него (повторную доставку).
+func (b *Broker) Publish(event model.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.ID <= b.lastID {
+		return
+	}
+	b.lastID = event.ID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	b.replay[b.next] = event
+	b.next = (b.next + 1) % len(b.replay)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for sub := range b.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe подписывает на события, подходящие под filter. События с ID больше lastID,
+// оставшиеся в буфере, возвращаются для повтора; подписка начинается сразу после них.
+func (b *Broker) Subscribe(filter Filter, lastID uint64) (*Subscription, []model.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		broker: b,
+		filter: filter,
+		events: make(chan model.Event, subscriberBuffer),
+	}
+	if b.closed {
+		close(sub.events)
+		return sub, nil
+	}
+	b.subscribers[sub] = struct{}{}
+
+	var missed []model.Event
+	if lastID > 0 {
+		for _, event := range b.buffered() {
+			if event.ID > lastID && sub.matches(event) {
+				missed = append(missed, event)
+			}
+		}
+	}
+	return sub, missed
+}
+
+// Close отключает всех подписчиков, чтобы потоки завершились при остановке сервера.
+// Новые подписки после Close сразу закрыты.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// Closed сообщает, остановлен ли брокер
+func (b *Broker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// buffered возвращает события буфера от старых к новым
+func (b *Broker) buffered() []model.Event {
+	if !b.full {
+		return b.replay[:b.next]
+	}
+	return append(append([]model.Event{}, b.replay[b.next:]...), b.replay[:b.next]...)
+}
+
+// remove отключает подписчика; вызывается под b.mu
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Subscription - подписка на события брокера
+type Subscription struct {
+	broker *Broker
+	filter Filter
+	events chan model.Event
+}
+
+// Events возвращает канал событий; канал закрывается при отключении подписчика
+func (s *Subscription) Events() <-chan model.Event {
+	return s.events
+}
+
+// Close отменяет подписку; повторный вызов безопасен
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+func (s *Subscription) matches(event model.Event) bool {
+	return s.filter == nil || s.filter(event)
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"testing"
+
+	"qna-api/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	b := NewBroker(10)
+
+	all, _ := b.Subscribe(nil, 0)
+	defer all.Close()
+	one, _ := b.Subscribe(ForQuestion(2), 0)
+	defer one.Close()
+
+	b.Publish(model.Event{ID: 7, Type: model.EventAnswerCreated, QuestionID: 1})
+	b.Publish(model.Event{ID: 9, Type: model.EventAnswerCreated, QuestionID: 2})
+
+	// ID берутся из журнала событий без изменений
+	first := <-all.Events()
+	second := <-all.Events()
+	assert.Equal(t, uint64(7), first.ID)
+	assert.Equal(t, uint64(9), second.ID)
+	assert.False(t, first.CreatedAt.IsZero())
+
+	event := <-one.Events()
+	assert.Equal(t, 2, event.QuestionID)
+	assert.Len(t, one.Events(), 0)
+}
+
+func TestBroker_DropsRedelivered(t *testing.T) {
+	b := NewBroker(10)
+	sub, _ := b.Subscribe(nil, 0)
+	defer sub.Close()
+
+	// Журнал доставляет события не менее одного раза: повтор и старые события отбрасываются
+	b.Publish(model.Event{ID: 3, Type: model.EventQuestionCreated, QuestionID: 1})
+	b.Publish(model.Event{ID: 3, Type: model.EventQuestionCreated, QuestionID: 1})
+	b.Publish(model.Event{ID: 2, Type: model.EventQuestionCreated, QuestionID: 2})
+	b.Publish(model.Event{ID: 4, Type: model.EventAnswerCreated, QuestionID: 1})
+
+	assert.Equal(t, uint64(3), (<-sub.Events()).ID)
+	assert.Equal(t, uint64(4), (<-sub.Events()).ID)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestBroker_Replay(t *testing.T) {
+	b := NewBroker(3)
+	for i := 1; i <= 5; i++ {
+		b.Publish(model.Event{ID: uint64(i), Type: model.EventQuestionUpdated, QuestionID: i})
+	}
+
+	// В буфере остались события 3, 4, 5
+	sub, missed := b.Subscribe(nil, 1)
+	defer sub.Close()
+	require.Len(t, missed, 3)
+	assert.Equal(t, uint64(3), missed[0].ID)
+	assert.Equal(t, uint64(5), missed[2].ID)
+
+	_, missed = b.Subscribe(ForQuestion(5), 3)
+	require.Len(t, missed, 1)
+	assert.Equal(t, uint64(5), missed[0].ID)
+
+	// Без Last-Event-ID повтора нет
+	_, missed = b.Subscribe(nil, 0)
+	assert.Empty(t, missed)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(10)
+	sub, _ := b.Subscribe(nil, 0)
+
+	// Publish не блокируется, даже если подписчик не читает
+	for i := 1; i <= subscriberBuffer+1; i++ {
+		b.Publish(model.Event{ID: uint64(i), Type: model.EventAnswerCreated, QuestionID: 1})
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+
+	// Повторное закрытие безопасно
+	sub.Close()
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker(10)
+	sub, _ := b.Subscribe(nil, 0)
+
+	b.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.True(t, b.Closed())
+
+	// После остановки новые подписки сразу закрыты, а публикация не блокируется
+	late, _ := b.Subscribe(nil, 0)
+	_, ok = <-late.Events()
+	assert.False(t, ok)
+	b.Publish(model.Event{ID: 1, Type: model.EventQuestionCreated, QuestionID: 1})
+	sub.Close()
+	late.Close()
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// GetAnswers - получить страницу ответов на вопрос
+func (h *Handler) GetAnswers(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	questionID, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	opts, err := parseAnswerListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	page, err := h.service.ListAnswers(r.Context(), questionID, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// CreateAnswer - создать ответ
+func (h *Handler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	questionID, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	req := model.CreateAnswerRequest{UserID: currentActor(r).Subject}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	answer, err := h.service.CreateAnswer(r.Context(), questionID, req)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, answer)
+}
+
+// GetAnswer - получить ответ по ID
+func (h *Handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	opts, err := parseGetAnswerOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	answer, err := h.service.GetAnswer(r.Context(), id, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, answer)
+}
+
+// UpdateAnswer - изменить ответ
+func (h *Handler) UpdateAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	var req model.UpdateAnswerRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	answer, err := h.service.UpdateAnswer(r.Context(), id, req, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, answer)
+}
+
+// GetAnswerRevisions - получить историю правок ответа
+func (h *Handler) GetAnswerRevisions(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	revisions, err := h.service.GetAnswerRevisions(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+// DeleteAnswer - удалить ответ
+func (h *Handler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	err = h.service.DeleteAnswer(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Answer deleted successfully"})
+}
+
+// RestoreAnswer - восстановить удаленный ответ
+func (h *Handler) RestoreAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	err = h.service.RestoreAnswer(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Deleted answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Answer restored successfully"})
+}
+
+// LockAnswer - заблокировать ответ
+func (h *Handler) LockAnswer(w http.ResponseWriter, r *http.Request) {
+	h.setAnswerLocked(w, r, true)
+}
+
+// UnlockAnswer - снять блокировку ответа
+func (h *Handler) UnlockAnswer(w http.ResponseWriter, r *http.Request) {
+	h.setAnswerLocked(w, r, false)
+}
+
+func (h *Handler) setAnswerLocked(w http.ResponseWriter, r *http.Request, locked bool) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	if err := h.service.SetAnswerLocked(r.Context(), id, locked); err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"locked": locked})
+}
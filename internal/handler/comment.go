@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// GetQuestionComments - получить страницу комментариев к вопросу
+func (h *Handler) GetQuestionComments(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	opts, err := parseCommentListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListQuestionComments(r.Context(), id, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// CreateQuestionComment - прокомментировать вопрос
+func (h *Handler) CreateQuestionComment(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	req, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	comment, err := h.service.CreateQuestionComment(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// GetAnswerComments - получить страницу комментариев к ответу
+func (h *Handler) GetAnswerComments(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	opts, err := parseCommentListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListAnswerComments(r.Context(), id, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// CreateAnswerComment - прокомментировать ответ
+func (h *Handler) CreateAnswerComment(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	req, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	comment, err := h.service.CreateAnswerComment(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// DeleteComment - удалить комментарий
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	err = h.service.DeleteComment(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Comment not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Comment deleted successfully"})
+}
+
+// decodeComment читает комментарий из тела запроса и пишет 400, если он некорректен
+func decodeComment(w http.ResponseWriter, r *http.Request) (model.CreateCommentRequest, bool) {
+	req := model.CreateCommentRequest{UserID: currentActor(r).Subject}
+	return req, decodeJSON(w, r, &req)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qna-api/internal/events"
+	"qna-api/internal/model"
+)
+
+// sseHeartbeat - интервал комментариев-пингов, чтобы прокси не закрывали простаивающий поток
+var sseHeartbeat = 15 * time.Second
+
+// Events - поток всех событий (Server-Sent Events)
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	h.streamEvents(w, r, nil)
+}
+
+// QuestionEvents - поток событий одного вопроса (Server-Sent Events)
+func (h *Handler) QuestionEvents(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	if _, err := h.service.GetQuestion(r.Context(), id, model.GetQuestionOptions{}); err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	h.streamEvents(w, r, events.ForQuestion(id))
+}
+
+// streamEvents отдает события брокера, начиная с пропущенных после Last-Event-ID
+func (h *Handler) streamEvents(w http.ResponseWriter, r *http.Request, filter events.Filter) {
+	flusher, ok := w.(http.Flusher)
+	if h.events == nil || !ok {
+		writeError(w, http.StatusServiceUnavailable, "Event stream not available")
+		return
+	}
+
+	lastID, err := parseLastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	sub, missed := h.events.Subscribe(filter, lastID)
+	defer sub.Close()
+
+	// Поток живет дольше таймаутов чтения и записи сервера
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Брокер отключил отстающего подписчика или остановлен; клиент переподключится с Last-Event-ID
+				return
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// parseLastEventID читает позицию возобновления из заголовка или параметра last_event_id
+func parseLastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+func writeEvent(w http.ResponseWriter, event model.Event) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
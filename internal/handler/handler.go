@@ -1,237 +1,416 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
+	"qna-api/internal/auth"
+	"qna-api/internal/events"
+	"qna-api/internal/health"
 	"qna-api/internal/model"
+	"qna-api/internal/policy"
+	"qna-api/internal/problem"
 	"qna-api/internal/service"
 
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
-	service service.ServiceInterface
+	service        service.ServiceInterface
+	events         *events.Broker
+	requestTimeout time.Duration
+	ready          func() bool
+	health         *health.Checker
+	metrics        http.Handler
 }
 
-func NewHandler(service service.ServiceInterface) *Handler {
-	return &Handler{service: service}
-}
-
-func (h *Handler) InitRoutes() *mux.Router {
-	router := mux.NewRouter()
+// Option настраивает обработчики
+type Option func(*Handler)
 
-	// Root and health routes
-	router.HandleFunc("/", h.rootHandler).Methods("GET")
-	router.HandleFunc("/health", h.healthCheck).Methods("GET")
-
-	// Questions routes
-	router.HandleFunc("/questions", h.GetQuestions).Methods("GET")
-	router.HandleFunc("/questions", h.CreateQuestion).Methods("POST")
-	router.HandleFunc("/questions/{id}", h.GetQuestion).Methods("GET")
-	router.HandleFunc("/questions/{id}", h.DeleteQuestion).Methods("DELETE")
-
-	// Answers routes
-	router.HandleFunc("/questions/{id}/answers", h.CreateAnswer).Methods("POST")
-	router.HandleFunc("/answers/{id}", h.GetAnswer).Methods("GET")
-	router.HandleFunc("/answers/{id}", h.DeleteAnswer).Methods("DELETE")
+// WithEvents подключает брокер событий для потоков SSE и WebSocket
+func WithEvents(broker *events.Broker) Option {
+	return func(h *Handler) {
+		h.events = broker
+	}
+}
 
-	return router
+// WithRequestTimeout ограничивает время обработки запроса; потоки событий не ограничиваются
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.requestTimeout = timeout
+	}
 }
 
-// Health check handler - работает без service
-func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status":  "healthy",
-		"service": "Q&A API",
-	})
+// WithReadiness подключает признак готовности сервера: во время остановки /health отвечает 503
+func WithReadiness(ready func() bool) Option {
+	return func(h *Handler) {
+		h.ready = ready
+	}
 }
 
-// Root handler - работает без service
-func (h *Handler) rootHandler(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"message": "Q&A API Service",
-		"version": "1.0.0",
-	})
+// WithHealth подключает проверки зависимостей для /readyz и /health
+func WithHealth(checker *health.Checker) Option {
+	return func(h *Handler) {
+		h.health = checker
+	}
 }
 
-// GetQuestions - получить все вопросы
-func (h *Handler) GetQuestions(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeJSON(w, http.StatusOK, []interface{}{})
-		return
+// WithMetrics подключает отдачу метрик Prometheus на /metrics
+func WithMetrics(metrics http.Handler) Option {
+	return func(h *Handler) {
+		h.metrics = metrics
 	}
+}
 
-	questions, err := h.service.GetAllQuestions()
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to get questions")
-		return
+func NewHandler(service service.ServiceInterface, opts ...Option) *Handler {
+	h := &Handler{service: service}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
+}
 
-	writeJSON(w, http.StatusOK, questions)
+// route - маршрут API и требования к вызывающему
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	rule    policy.Rule
 }
 
-// CreateQuestion - создать вопрос
-func (h *Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
+// routes - таблица маршрутов с правами доступа.
+// Автора контента дополнительно проверяет сервис.
+func (h *Handler) routes() []route {
+	return []route{
+		// Root and health routes
+		{"GET", "/", h.rootHandler, policy.Public},
+		{"GET", "/health", h.healthCheck, policy.Public},
+		{"GET", "/livez", h.livez, policy.Public},
+		{"GET", "/readyz", h.readyz, policy.Public},
+		{"GET", "/metrics", h.metricsHandler, policy.Public},
+
+		// Questions routes
+		{"GET", "/questions", h.GetQuestions, policy.Public},
+		{"POST", "/questions", h.CreateQuestion, policy.Authenticated},
+		{"GET", "/questions/similar", h.GetSimilarQuestions, policy.Public},
+		{"GET", "/questions/{id}", h.GetQuestion, policy.Public},
+		{"PATCH", "/questions/{id}", h.UpdateQuestion, policy.Authenticated},
+		{"DELETE", "/questions/{id}", h.DeleteQuestion, policy.Authenticated},
+		{"GET", "/questions/{id}/revisions", h.GetQuestionRevisions, policy.Public},
+		{"POST", "/questions/{id}/restore", h.RestoreQuestion, policy.Authenticated},
+		{"POST", "/questions/{id}/lock", h.LockQuestion, policy.Moderator},
+		{"DELETE", "/questions/{id}/lock", h.UnlockQuestion, policy.Moderator},
+		{"POST", "/questions/{id}/vote", h.VoteQuestion, policy.Authenticated},
+		{"POST", "/questions/{id}/accept/{answerId}", h.AcceptAnswer, policy.Authenticated},
+		{"DELETE", "/questions/{id}/accept", h.UnacceptAnswer, policy.Authenticated},
+		{"GET", "/questions/{id}/events", h.QuestionEvents, policy.Public},
+		{"GET", "/questions/{id}/comments", h.GetQuestionComments, policy.Public},
+		{"POST", "/questions/{id}/comments", h.CreateQuestionComment, policy.Authenticated},
+
+		// Answers routes
+		{"GET", "/questions/{id}/answers", h.GetAnswers, policy.Public},
+		{"POST", "/questions/{id}/answers", h.CreateAnswer, policy.Authenticated},
+		{"GET", "/answers/{id}", h.GetAnswer, policy.Public},
+		{"PATCH", "/answers/{id}", h.UpdateAnswer, policy.Authenticated},
+		{"DELETE", "/answers/{id}", h.DeleteAnswer, policy.Authenticated},
+		{"GET", "/answers/{id}/revisions", h.GetAnswerRevisions, policy.Public},
+		{"POST", "/answers/{id}/restore", h.RestoreAnswer, policy.Authenticated},
+		{"POST", "/answers/{id}/lock", h.LockAnswer, policy.Moderator},
+		{"DELETE", "/answers/{id}/lock", h.UnlockAnswer, policy.Moderator},
+		{"POST", "/answers/{id}/vote", h.VoteAnswer, policy.Authenticated},
+		{"GET", "/answers/{id}/comments", h.GetAnswerComments, policy.Public},
+		{"POST", "/answers/{id}/comments", h.CreateAnswerComment, policy.Authenticated},
+
+		// Comments routes
+		{"DELETE", "/comments/{id}", h.DeleteComment, policy.Authenticated},
+
+		// Tags routes
+		{"GET", "/tags", h.GetTags, policy.Public},
+		{"PATCH", "/tags/{name}", h.RenameTag, policy.Admin},
+		{"POST", "/tags/{name}/merge", h.MergeTag, policy.Admin},
+
+		// Webhooks routes
+		{"GET", "/webhooks", h.GetWebhooks, policy.Admin},
+		{"POST", "/webhooks", h.CreateWebhook, policy.Admin},
+		{"DELETE", "/webhooks/{id}", h.DeleteWebhook, policy.Admin},
+		{"GET", "/webhooks/{id}/deliveries", h.GetWebhookDeliveries, policy.Admin},
+
+		// Events routes
+		{"GET", "/events", h.Events, policy.Public},
+		{"GET", "/ws", h.WebSocket, policy.Public},
+
+		// Search routes
+		{"GET", "/search", h.Search, policy.Public},
+
+		// Users routes
+		{"GET", "/users/{id}/questions", h.GetUserQuestions, policy.Public},
+		{"GET", "/users/{id}/answers", h.GetUserAnswers, policy.Public},
+		{"GET", "/users/{id}/role", h.GetUserRole, policy.Admin},
+		{"PUT", "/users/{id}/role", h.SetUserRole, policy.Admin},
+
+		// URL вопроса со slug; стоит последним, чтобы не перекрывать /questions/{id}/answers и др.
+		{"GET", "/questions/{id}/{slug}", h.GetQuestion, policy.Public},
 	}
+}
 
-	var req model.CreateQuestionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
+func (h *Handler) InitRoutes() *mux.Router {
+	router := mux.NewRouter()
 
-	if req.Text == "" {
-		writeError(w, http.StatusBadRequest, "Question text is required")
-		return
+	for _, rt := range h.routes() {
+		handler := guard(rt.rule, rt.handler)
+		if h.requestTimeout > 0 && !streamRoutes[rt.path] {
+			handler = withTimeout(h.requestTimeout, handler)
+		}
+		router.HandleFunc(rt.path, handler).Methods(rt.method)
 	}
 
-	question, err := h.service.CreateQuestion(req)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to create question")
-		return
-	}
+	return router
+}
 
-	writeJSON(w, http.StatusCreated, question)
+// streamRoutes - долгоживущие потоки событий, к которым не применяется таймаут запроса
+var streamRoutes = map[string]bool{
+	"/events":                true,
+	"/ws":                    true,
+	"/questions/{id}/events": true,
 }
 
-// GetQuestion - получить вопрос по ID
-func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
+// withTimeout задает запросу срок: по его истечении контекст отменяется и запросы к базе прерываются
+func withTimeout(timeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next(w, r.WithContext(ctx))
 	}
+}
 
-	id, err := getIDFromRequest(r)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid question ID")
-		return
+// guard пропускает запрос к обработчику, только если вызывающий удовлетворяет правилу
+func guard(rule policy.Rule, next http.HandlerFunc) http.HandlerFunc {
+	if rule == policy.Public {
+		return next
 	}
-
-	question, err := h.service.GetQuestion(id)
-	if err != nil {
-		writeError(w, http.StatusNotFound, "Question not found")
-		return
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := auth.FromContext(r.Context())
+		if !checkRule(w, rule, identity, ok) {
+			return
+		}
+		next(w, r)
 	}
-
-	writeJSON(w, http.StatusOK, question)
 }
 
-// DeleteQuestion - удалить вопрос
-func (h *Handler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
+// checkRule пишет 401/403, если вызывающий не удовлетворяет правилу
+func checkRule(w http.ResponseWriter, rule policy.Rule, identity auth.Identity, authenticated bool) bool {
+	switch err := rule.Check(identity, authenticated); {
+	case errors.Is(err, policy.ErrUnauthenticated):
+		writeError(w, http.StatusUnauthorized, "Authentication required")
+		return false
+	case errors.Is(err, policy.ErrInsufficientRole):
+		writeErrorCode(w, http.StatusForbidden, "insufficient_role",
+			fmt.Sprintf("This action requires the %s role", rule.MinRole))
+		return false
 	}
+	return true
+}
 
-	id, err := getIDFromRequest(r)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid question ID")
+// Health check handler - работает без service; формат ответа сохранен для старых проверок
+func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
+	if h.ready != nil && !h.ready() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status":  "shutting down",
+			"service": "Q&A API",
+		})
 		return
 	}
-
-	if err := h.service.DeleteQuestion(id); err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to delete question")
+	if report := h.checkDependencies(r.Context()); !report.OK() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status":  "unhealthy",
+			"service": "Q&A API",
+		})
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "healthy",
+		"service": "Q&A API",
+	})
+}
 
-	writeJSON(w, http.StatusOK, map[string]string{"message": "Question deleted successfully"})
+// livez - процесс жив и обслуживает запросы; зависимости не проверяются,
+// чтобы сбой базы не приводил к перезапуску всех экземпляров
+func (h *Handler) livez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.Report{Status: health.StatusOK})
 }
 
-// CreateAnswer - создать ответ
-func (h *Handler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
+// readyz - экземпляр готов принимать запросы: не останавливается и зависимости доступны.
+// С ?verbose в ответ входят результаты проверок с задержкой и подробностями.
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.checkDependencies(r.Context())
+	if h.ready != nil && !h.ready() {
+		report.Status = health.StatusFail
+		report.Checks = append([]health.Result{{Name: "server", Status: health.StatusFail, Error: "shutting down"}},
+			report.Checks...)
 	}
 
-	questionID, err := getIDFromRequest(r)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid question ID")
-		return
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
 	}
-
-	var req model.CreateAnswerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request body")
-		return
+	if _, verbose := r.URL.Query()["verbose"]; !verbose {
+		report.Checks = nil
 	}
+	writeJSON(w, status, report)
+}
 
-	if req.Text == "" || req.UserID == "" {
-		writeError(w, http.StatusBadRequest, "Answer text and user ID are required")
+// metricsHandler отдает метрики Prometheus, если они включены
+func (h *Handler) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.metrics == nil {
+		writeError(w, http.StatusNotFound, "Metrics are not enabled")
 		return
 	}
+	h.metrics.ServeHTTP(w, r)
+}
 
-	answer, err := h.service.CreateAnswer(questionID, req)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to create answer")
-		return
+// checkDependencies выполняет проверки зависимостей; без проверок зависимости считаются доступными
+func (h *Handler) checkDependencies(ctx context.Context) health.Report {
+	if h.health == nil {
+		return health.Report{Status: health.StatusOK, Checks: []health.Result{}}
 	}
+	return h.health.Run(ctx)
+}
 
-	writeJSON(w, http.StatusCreated, answer)
+// Root handler - работает без service
+func (h *Handler) rootHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Q&A API Service",
+		"version": "1.0.0",
+	})
 }
 
-// GetAnswer - получить ответ по ID
-func (h *Handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
-	}
+// Utility functions
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
 
-	id, err := getIDFromRequest(r)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid answer ID")
-		return
-	}
+// writeError пишет ошибку в формате RFC 7807 с машиночитаемым кодом, соответствующим статусу
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorCode(w, status, errorCode(status), message)
+}
 
-	answer, err := h.service.GetAnswer(id)
-	if err != nil {
-		writeError(w, http.StatusNotFound, "Answer not found")
-		return
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
 	}
+	problem.Write(w, problem.New(status, code, message))
+}
 
-	writeJSON(w, http.StatusOK, answer)
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	case http.StatusGatewayTimeout:
+		return "timeout"
+	}
+	return "internal_error"
 }
 
-// DeleteAnswer - удалить ответ
-func (h *Handler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
-	if h.service == nil {
-		writeError(w, http.StatusServiceUnavailable, "Service not available")
-		return
+// writeServiceError пишет ошибку сервиса со статусом по ее категории.
+// notFound - сообщение для ненайденной записи; причина внутренних ошибок клиенту не раскрывается,
+// но вместе с отправленным сообщением попадает в лог.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, notFound string) {
+	p := serviceProblem(err, notFound)
+
+	level := slog.LevelInfo
+	if p.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
 	}
+	slog.Log(r.Context(), level, "Request failed",
+		"status", p.Status, "code", p.Code, "message", p.Detail, "error", err)
 
-	id, err := getIDFromRequest(r)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid answer ID")
-		return
+	problem.Write(w, p)
+}
+
+// serviceProblem описывает ошибку сервиса для клиента
+func serviceProblem(err error, notFound string) *problem.Problem {
+	var serviceErr *service.Error
+	switch {
+	case errors.As(err, &serviceErr):
+		p := problem.New(serviceStatus(serviceErr.Kind), serviceErr.Code, err.Error())
+		p.Errors = serviceErr.Fields
+		return p
+	case errors.Is(err, service.ErrForbidden):
+		return problem.New(http.StatusForbidden, errorCode(http.StatusForbidden), "Only the author or a moderator can change this content")
+	case errors.Is(err, service.ErrNotFound):
+		return problem.New(http.StatusNotFound, errorCode(http.StatusNotFound), notFound)
+	case errors.Is(err, service.ErrConflict):
+		return problem.New(http.StatusConflict, errorCode(http.StatusConflict), "The change conflicts with existing data")
+	case errors.Is(err, service.ErrValidation):
+		return problem.New(http.StatusBadRequest, errorCode(http.StatusBadRequest), err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return problem.New(http.StatusGatewayTimeout, errorCode(http.StatusGatewayTimeout), "Request timed out")
 	}
+	return problem.New(http.StatusInternalServerError, errorCode(http.StatusInternalServerError), "Internal server error")
+}
 
-	if err := h.service.DeleteAnswer(id); err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to delete answer")
-		return
+// serviceStatus возвращает HTTP-статус для категории ошибки сервиса
+func serviceStatus(kind error) int {
+	switch kind {
+	case service.ErrNotFound:
+		return http.StatusNotFound
+	case service.ErrConflict:
+		return http.StatusConflict
+	case service.ErrValidation:
+		return http.StatusBadRequest
+	case service.ErrForbidden:
+		return http.StatusForbidden
 	}
+	return http.StatusInternalServerError
+}
 
-	writeJSON(w, http.StatusOK, map[string]string{"message": "Answer deleted successfully"})
+// writeValidationError пишет 400 с ошибками полей запроса
+func writeValidationError(w http.ResponseWriter, fields []model.FieldError) {
+	p := problem.New(http.StatusBadRequest, service.ErrInvalidRequest.Code, "Request validation failed")
+	p.Errors = fields
+	problem.Write(w, p)
 }
 
-// Utility functions
-func writeJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+// requireModerator пишет 401/403, если вызывающий не модератор
+func requireModerator(w http.ResponseWriter, r *http.Request) bool {
+	identity, ok := auth.FromContext(r.Context())
+	return checkRule(w, policy.Moderator, identity, ok)
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{"error": message})
+// currentActor возвращает пользователя запроса; для маршрутов с правилом guard гарантирует его наличие
+func currentActor(r *http.Request) auth.Identity {
+	identity, _ := auth.FromContext(r.Context())
+	return identity
 }
 
 func getIDFromRequest(r *http.Request) (int, error) {
+	return getIntVar(r, "id")
+}
+
+// getIntVar возвращает числовую переменную пути
+func getIntVar(r *http.Request, name string) (int, error) {
 	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := strconv.Atoi(vars[name])
 	if err != nil {
 		return 0, err
 	}
@@ -1,18 +1,32 @@
 package handler
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"qna-api/internal/auth"
+	"qna-api/internal/events"
+	"qna-api/internal/health"
+	"qna-api/internal/logging"
 	"qna-api/internal/model"
+	"qna-api/internal/problem"
 	"qna-api/internal/service"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockService реализует service.ServiceInterface
@@ -20,47 +34,295 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) GetAllQuestions() ([]model.Question, error) {
-	args := m.Called()
-	return args.Get(0).([]model.Question), args.Error(1)
+func (m *MockService) ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.QuestionPage), args.Error(1)
 }
 
-func (m *MockService) GetQuestion(id int) (*model.Question, error) {
-	args := m.Called(id)
+func (m *MockService) GetQuestion(ctx context.Context, id int, opts model.GetQuestionOptions) (*model.Question, error) {
+	args := m.Called(ctx, id, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Question), args.Error(1)
 }
 
-func (m *MockService) CreateQuestion(req model.CreateQuestionRequest) (*model.Question, error) {
-	args := m.Called(req)
+func (m *MockService) CreateQuestion(ctx context.Context, req model.CreateQuestionRequest) (*model.Question, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*model.Question), args.Error(1)
+}
+
+func (m *MockService) SimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error) {
+	args := m.Called(ctx, title, limit)
+	return args.Get(0).([]model.Question), args.Error(1)
+}
+
+func (m *MockService) UpdateQuestion(ctx context.Context, id int, req model.UpdateQuestionRequest, actor auth.Identity) (*model.Question, error) {
+	args := m.Called(ctx, id, req, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*model.Question), args.Error(1)
 }
 
-func (m *MockService) DeleteQuestion(id int) error {
-	args := m.Called(id)
+func (m *MockService) DeleteQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	args := m.Called(ctx, id, actor)
+	return args.Error(0)
+}
+
+func (m *MockService) SetQuestionLocked(ctx context.Context, id int, locked bool) error {
+	args := m.Called(ctx, id, locked)
 	return args.Error(0)
 }
 
-func (m *MockService) CreateAnswer(questionID int, req model.CreateAnswerRequest) (*model.Answer, error) {
-	args := m.Called(questionID, req)
+func (m *MockService) AcceptAnswer(ctx context.Context, questionID, answerID int, actor auth.Identity) (*model.Question, error) {
+	args := m.Called(ctx, questionID, answerID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Question), args.Error(1)
+}
+
+func (m *MockService) UnacceptAnswer(ctx context.Context, questionID int, actor auth.Identity) (*model.Question, error) {
+	args := m.Called(ctx, questionID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Question), args.Error(1)
+}
+
+func (m *MockService) CreateAnswer(ctx context.Context, questionID int, req model.CreateAnswerRequest) (*model.Answer, error) {
+	args := m.Called(ctx, questionID, req)
+	return args.Get(0).(*model.Answer), args.Error(1)
+}
+
+func (m *MockService) ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	args := m.Called(ctx, questionID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AnswerPage), args.Error(1)
+}
+
+func (m *MockService) ListUserAnswers(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	args := m.Called(ctx, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AnswerPage), args.Error(1)
+}
+
+func (m *MockService) GetAnswer(ctx context.Context, id int, opts model.GetAnswerOptions) (*model.Answer, error) {
+	args := m.Called(ctx, id, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*model.Answer), args.Error(1)
 }
 
-func (m *MockService) GetAnswer(id int) (*model.Answer, error) {
-	args := m.Called(id)
+func (m *MockService) UpdateAnswer(ctx context.Context, id int, req model.UpdateAnswerRequest, actor auth.Identity) (*model.Answer, error) {
+	args := m.Called(ctx, id, req, actor)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Answer), args.Error(1)
 }
 
-func (m *MockService) DeleteAnswer(id int) error {
-	args := m.Called(id)
+func (m *MockService) DeleteAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	args := m.Called(ctx, id, actor)
+	return args.Error(0)
+}
+
+func (m *MockService) SetAnswerLocked(ctx context.Context, id int, locked bool) error {
+	args := m.Called(ctx, id, locked)
+	return args.Error(0)
+}
+
+func (m *MockService) RestoreQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	args := m.Called(ctx, id, actor)
+	return args.Error(0)
+}
+
+func (m *MockService) RestoreAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	args := m.Called(ctx, id, actor)
+	return args.Error(0)
+}
+
+func (m *MockService) PurgeDeleted(ctx context.Context, retention time.Duration) (*model.PurgeResult, error) {
+	args := m.Called(ctx, retention)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PurgeResult), args.Error(1)
+}
+
+func (m *MockService) GetQuestionRevisions(ctx context.Context, questionID int) ([]model.Revision, error) {
+	args := m.Called(ctx, questionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockService) GetAnswerRevisions(ctx context.Context, answerID int) ([]model.Revision, error) {
+	args := m.Called(ctx, answerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockService) VoteQuestion(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	args := m.Called(ctx, id, value, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VoteResult), args.Error(1)
+}
+
+func (m *MockService) VoteAnswer(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	args := m.Called(ctx, id, value, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VoteResult), args.Error(1)
+}
+
+func (m *MockService) CreateQuestionComment(ctx context.Context, questionID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	args := m.Called(ctx, questionID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockService) CreateAnswerComment(ctx context.Context, answerID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	args := m.Called(ctx, answerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockService) ListQuestionComments(ctx context.Context, questionID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	args := m.Called(ctx, questionID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CommentPage), args.Error(1)
+}
+
+func (m *MockService) ListAnswerComments(ctx context.Context, answerID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	args := m.Called(ctx, answerID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CommentPage), args.Error(1)
+}
+
+func (m *MockService) DeleteComment(ctx context.Context, id int, actor auth.Identity) error {
+	args := m.Called(ctx, id, actor)
+	return args.Error(0)
+}
+
+func (m *MockService) CreateWebhook(ctx context.Context, req model.CreateWebhookRequest, actor auth.Identity) (*model.Webhook, error) {
+	args := m.Called(ctx, req, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockService) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *MockService) DeleteWebhook(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockService) ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error) {
+	args := m.Called(ctx, webhookID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DeliveryPage), args.Error(1)
+}
+
+func (m *MockService) ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Tag), args.Error(1)
+}
+
+func (m *MockService) ResolveTagNames(ctx context.Context, names []string) ([]string, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockService) RenameTag(ctx context.Context, name, newName string) (*model.Tag, error) {
+	args := m.Called(ctx, name, newName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockService) MergeTags(ctx context.Context, name, into string) (*model.Tag, error) {
+	args := m.Called(ctx, name, into)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockService) Search(ctx context.Context, query string, opts model.SearchOptions) (*model.SearchPage, error) {
+	args := m.Called(ctx, query, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SearchPage), args.Error(1)
+}
+
+func (m *MockService) GetUserRole(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) SetUserRole(ctx context.Context, userID string, role auth.Role, actor auth.Identity) (*model.UserRole, error) {
+	args := m.Called(ctx, userID, role, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserRole), args.Error(1)
+}
+
+// testUserID - идентификатор пользователя в формате UUID, как в колонках user_id
+const testUserID = "3f1e9c2a-5b7d-4e8f-a1c3-9d2b6e4f7a10"
+
+// withUser имитирует запрос аутентифицированного пользователя с ролью user
+func withUser(req *http.Request, userID string) *http.Request {
+	return withRole(req, userID, auth.RoleUser)
+}
+
+// withRole имитирует запрос аутентифицированного пользователя с заданной ролью
+func withRole(req *http.Request, userID string, role auth.Role) *http.Request {
+	return req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: userID, Role: role}))
+}
+
 func TestHealthCheck(t *testing.T) {
 	// Health check не требует service, можно передать nil
 	// Но так как мы используем интерфейс, нужно передать nil явно
@@ -81,23 +343,103 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "healthy", response["status"])
 }
 
+func TestHealthCheck_NotReady(t *testing.T) {
+	ready := false
+	handler := NewHandler(nil, WithReadiness(func() bool { return ready }))
+
+	// Во время остановки сервер снимает готовность до закрытия приема соединений
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	ready = true
+	rr = httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestHealthProbes(t *testing.T) {
+	dbErr := errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	dbUp := true
+	checker := health.NewChecker(time.Second, health.Check{
+		Name: "database",
+		Run: func(ctx context.Context) (interface{}, error) {
+			if !dbUp {
+				return nil, dbErr
+			}
+			return health.PoolStats{MaxOpen: 10, Open: 1}, nil
+		},
+	})
+	router := NewHandler(nil, WithHealth(checker)).InitRoutes()
+
+	get := func(path string) (*httptest.ResponseRecorder, health.Report) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		var report health.Report
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr, report
+	}
+
+	rr, report := get("/readyz")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Empty(t, report.Checks)
+
+	rr, report = get("/readyz?verbose")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Contains(t, rr.Body.String(), `"max_open":10`)
+
+	// База недоступна: экземпляр не готов, но жив
+	dbUp = false
+	rr, report = get("/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, dbErr.Error(), report.Checks[0].Error)
+
+	rr, _ = get("/livez")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr, _ = get("/health")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"unhealthy"`)
+}
+
+func TestReadyz_ShuttingDown(t *testing.T) {
+	handler := NewHandler(nil, WithReadiness(func() bool { return false }))
+
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, httptest.NewRequest("GET", "/readyz?verbose", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	var report health.Report
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	require.NotEmpty(t, report.Checks)
+	assert.Equal(t, "shutting down", report.Checks[0].Error)
+}
+
 func TestCreateQuestion_Success(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
 	expectedQuestion := &model.Question{
-		ID:   1,
-		Text: "Test question",
+		ID:    1,
+		Title: "Test question",
+		Slug:  "test-question",
+		Body:  "Question body",
 	}
 
-	mockService.On("CreateQuestion", mock.AnythingOfType("model.CreateQuestionRequest")).
+	// Автор берется из токена, а не из тела запроса
+	mockService.On("CreateQuestion", mock.Anything, model.CreateQuestionRequest{UserID: testUserID, Title: "Test question", Body: "Question body"}).
 		Return(expectedQuestion, nil)
 
-	reqBody := map[string]string{"text": "Test question"}
+	reqBody := map[string]string{"title": "Test question", "body": "Question body"}
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest("POST", "/questions", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, testUserID)
 	rr := httptest.NewRecorder()
 
 	router := handler.InitRoutes()
@@ -109,7 +451,8 @@ func TestCreateQuestion_Success(t *testing.T) {
 	json.Unmarshal(rr.Body.Bytes(), &response)
 
 	assert.Equal(t, 1, response.ID)
-	assert.Equal(t, "Test question", response.Text)
+	assert.Equal(t, "Test question", response.Title)
+	assert.Equal(t, "test-question", response.Slug)
 
 	mockService.AssertExpectations(t)
 }
@@ -118,13 +461,16 @@ func TestGetQuestions_Success(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
-	expectedQuestions := []model.Question{
-		{ID: 1, Text: "Question 1"},
-		{ID: 2, Text: "Question 2"},
+	expectedPage := &model.QuestionPage{
+		Items: []model.Question{
+			{ID: 1, Title: "Question 1"},
+			{ID: 2, Title: "Question 2"},
+		},
+		NextCursor: "next",
 	}
 
 	// Настраиваем mock
-	mockService.On("GetAllQuestions").Return(expectedQuestions, nil)
+	mockService.On("ListQuestions", mock.Anything, mock.AnythingOfType("model.QuestionListOptions")).Return(expectedPage, nil)
 
 	// Выполняем запрос
 	req := httptest.NewRequest("GET", "/questions", nil)
@@ -136,78 +482,1645 @@ func TestGetQuestions_Success(t *testing.T) {
 	// Проверяем результат
 	assert.Equal(t, http.StatusOK, rr.Code)
 
-	var response []model.Question
+	var response model.QuestionPage
 	json.Unmarshal(rr.Body.Bytes(), &response)
 
-	assert.Len(t, response, 2)
-	assert.Equal(t, "Question 1", response[0].Text)
-	assert.Equal(t, "Question 2", response[1].Text)
+	assert.Len(t, response.Items, 2)
+	assert.Equal(t, "Question 1", response.Items[0].Title)
+	assert.Equal(t, "Question 2", response.Items[1].Title)
+	assert.Equal(t, "next", response.NextCursor)
 
 	mockService.AssertExpectations(t)
 }
 
-func TestGetQuestion_NotFound(t *testing.T) {
+func TestGetQuestions_ListOptions(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
-	// Настраиваем mock для возврата ошибки
-	mockService.On("GetQuestion", 999).Return(nil, assert.AnError)
+	cursor := model.Cursor{Sort: "oldest", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: 5}
 
-	// Выполняем запрос
-	req := httptest.NewRequest("GET", "/questions/999", nil)
+	mockService.On("ListQuestions", mock.Anything, mock.MatchedBy(func(opts model.QuestionListOptions) bool {
+		return opts.Limit == 10 &&
+			opts.Sort == model.QuestionSortOldest &&
+			opts.After != nil && opts.After.ID == 5 &&
+			opts.CreatedAfter != nil && opts.CreatedAfter.Year() == 2023
+	})).Return(&model.QuestionPage{Items: []model.Question{}}, nil)
+
+	req := httptest.NewRequest("GET", "/questions?limit=10&sort=oldest&created_after=2023-06-01T00:00:00Z&cursor="+cursor.Encode(), nil)
 	rr := httptest.NewRecorder()
 
 	router := handler.InitRoutes()
 	router.ServeHTTP(rr, req)
 
-	// Проверяем что получили 404
-	assert.Equal(t, http.StatusNotFound, rr.Code)
-
+	assert.Equal(t, http.StatusOK, rr.Code)
 	mockService.AssertExpectations(t)
 }
 
-func TestCreateAnswer_Success(t *testing.T) {
+func TestGetQuestions_InvalidParams(t *testing.T) {
 	mockService := new(MockService)
 	handler := NewHandler(mockService)
 
-	expectedAnswer := &model.Answer{
-		ID:         1,
-		QuestionID: 1,
-		UserID:     "user-123",
-		Text:       "Test answer",
-	}
+	newest := model.Cursor{Sort: "newest", CreatedAt: time.Now(), ID: 1}.Encode()
 
-	// Настраиваем mock
-	mockService.On("CreateAnswer", 1, mock.AnythingOfType("model.CreateAnswerRequest")).
-		Return(expectedAnswer, nil)
+	for _, query := range []string{
+		"limit=0",
+		"limit=abc",
+		"sort=random",
+		"cursor=garbage",
+		"sort=oldest&cursor=" + newest,
+		"created_before=yesterday",
+	} {
+		req := httptest.NewRequest("GET", "/questions?"+query, nil)
+		rr := httptest.NewRecorder()
 
-	// Подготавливаем запрос
-	reqBody := map[string]string{
-		"user_id": "user-123",
-		"text":    "Test answer",
+		router := handler.InitRoutes()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
 	}
-	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest("POST", "/questions/1/answers", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
+	mockService.AssertNotCalled(t, "ListQuestions", mock.Anything, mock.Anything)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, WithRequestTimeout(time.Second))
+
+	// Сервис получает контекст запроса со сроком; истекший срок - 504, а не 500
+	withDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+		_, ok := ctx.Deadline()
+		return ok
+	})
+	mockService.On("GetQuestion", withDeadline, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortOldest}).
+		Return(nil, fmt.Errorf("query questions: %w", context.DeadlineExceeded))
+
+	req := httptest.NewRequest("GET", "/questions/1", nil)
 	rr := httptest.NewRecorder()
 
-	// Устанавливаем параметры маршрута для mux
-	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+
+	var response problem.Problem
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "timeout", response.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestion_InternalError(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	// Сбой базы не выдается за отсутствие вопроса и не раскрывается клиенту
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortOldest}).
+		Return(nil, errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+
+	req := httptest.NewRequest("GET", "/questions/1", nil)
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.NotContains(t, rr.Body.String(), "connection refused")
+
+	var response problem.Problem
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusInternalServerError, response.Status)
+	assert.Equal(t, "internal_error", response.Code)
+	assert.Equal(t, "Internal Server Error", response.Title)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestServiceError_Logged(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info")
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortOldest}).
+		Return(nil, fmt.Errorf("load question: %w", errors.New("connection refused")))
 
-	// Выполняем запрос
 	router := handler.InitRoutes()
+	router.Use(logging.RequestID())
+	req := httptest.NewRequest("GET", "/questions/1", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	// Проверяем результат
-	assert.Equal(t, http.StatusCreated, rr.Code)
+	// Клиент видит только общее сообщение, а в лог попадает исходная ошибка
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "connection refused")
 
-	var response model.Answer
-	json.Unmarshal(rr.Body.Bytes(), &response)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "Request failed", entry["msg"])
+	assert.Equal(t, "load question: connection refused", entry["error"])
+	assert.Equal(t, "Internal server error", entry["message"])
+	assert.Equal(t, "internal_error", entry["code"])
+	assert.Equal(t, "req-42", entry["request_id"])
+}
 
-	assert.Equal(t, 1, response.ID)
-	assert.Equal(t, "Test answer", response.Text)
-	assert.Equal(t, "user-123", response.UserID)
+func TestGetQuestion_NotFound(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
 
-	mockService.AssertExpectations(t)
+	// Настраиваем mock для возврата ошибки
+	mockService.On("GetQuestion", mock.Anything, 999, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortOldest}).Return(nil, service.ErrNotFound)
+
+	// Выполняем запрос
+	req := httptest.NewRequest("GET", "/questions/999", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	// Проверяем что получили 404
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestion_IncludeAnswers(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedQuestion := &model.Question{
+		ID:      1,
+		Title:   "Test question",
+		Answers: []model.Answer{{ID: 1, QuestionID: 1, Text: "Test answer"}},
+	}
+
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersLimit: 5, AnswersSort: model.AnswerSortOldest}).
+		Return(expectedQuestion, nil)
+
+	req := httptest.NewRequest("GET", "/questions/1?include=answers&answers_limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.Question
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	assert.Len(t, response.Answers, 1)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestion_AnswersSort(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	// Ответы внутри вопроса сортируются так же, как в GET /questions/{id}/answers
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortScore}).
+		Return(&model.Question{ID: 1, Title: "Test question"}, nil)
+
+	router := handler.InitRoutes()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1?answers_sort=score", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1?answers_sort=random", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "answers_sort")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestion_AnswersByDefault(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	accepted := 2
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersSort: model.AnswerSortOldest}).
+		Return(&model.Question{
+			ID:               1,
+			AcceptedAnswerID: &accepted,
+			Answers:          []model.Answer{{ID: 2, QuestionID: 1}, {ID: 1, QuestionID: 1}},
+		}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.Question
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	require.Len(t, response.Answers, 2)
+	assert.Equal(t, 2, response.Answers[0].ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestion_IncludeNone(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	// Без include ответы включаются, include=none их отключает
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{AnswersSort: model.AnswerSortOldest}).
+		Return(&model.Question{ID: 1, Title: "Test question"}, nil)
+
+	router := handler.InitRoutes()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1?include=none", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), `"answers"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1?include=answers,none", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAnswers_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedPage := &model.AnswerPage{
+		Items:      []model.Answer{{ID: 3, QuestionID: 1, Text: "Answer"}},
+		NextCursor: "next",
+	}
+
+	mockService.On("ListAnswers", mock.Anything, 1, model.AnswerListOptions{Limit: 1, Sort: model.AnswerSortNewest}).
+		Return(expectedPage, nil)
+
+	req := httptest.NewRequest("GET", "/questions/1/answers?limit=1&sort=newest", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.AnswerPage
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, "next", response.NextCursor)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAnswers_QuestionNotFound(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListAnswers", mock.Anything, 999, mock.AnythingOfType("model.AnswerListOptions")).
+		Return(nil, service.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/questions/999/answers", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateAnswer_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedAnswer := &model.Answer{
+		ID:         1,
+		QuestionID: 1,
+		UserID:     testUserID,
+		Text:       "Test answer",
+	}
+
+	// Настраиваем mock
+	mockService.On("CreateAnswer", mock.Anything, 1, model.CreateAnswerRequest{UserID: testUserID, Text: "Test answer"}).
+		Return(expectedAnswer, nil)
+
+	// Подготавливаем запрос
+	reqBody := map[string]string{
+		"text": "Test answer",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/questions/1/answers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, testUserID)
+	rr := httptest.NewRecorder()
+
+	// Устанавливаем параметры маршрута для mux
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	// Выполняем запрос
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	// Проверяем результат
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var response model.Answer
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	assert.Equal(t, 1, response.ID)
+	assert.Equal(t, "Test answer", response.Text)
+	assert.Equal(t, testUserID, response.UserID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateQuestion_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedQuestion := &model.Question{ID: 1, Title: "Updated question"}
+
+	actor := auth.Identity{Subject: "user-123", Role: auth.RoleUser}
+	mockService.On("UpdateQuestion", mock.Anything, 1, mock.MatchedBy(func(req model.UpdateQuestionRequest) bool {
+		return req.Title != nil && *req.Title == "Updated question" && req.Body == nil
+	}), actor).Return(expectedQuestion, nil)
+
+	body, _ := json.Marshal(map[string]string{"title": "Updated question"})
+
+	req := httptest.NewRequest("PATCH", "/questions/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.Question
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	assert.Equal(t, "Updated question", response.Title)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAnswer_EmptyText(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"text": ""})
+
+	req := httptest.NewRequest("PATCH", "/answers/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertNotCalled(t, "UpdateAnswer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestQuestion_DeprecatedTextField(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	// Клиенты до появления заголовков присылают text: он становится телом,
+	// а первая строка - заголовком
+	mockService.On("CreateQuestion", mock.Anything, model.CreateQuestionRequest{
+		UserID: testUserID,
+		Title:  "How to use Go?",
+		Body:   "How to use Go?\nDetails",
+	}).Return(&model.Question{ID: 1}, nil)
+	mockService.On("UpdateQuestion", mock.Anything, 1, mock.MatchedBy(func(req model.UpdateQuestionRequest) bool {
+		return req.Title == nil && req.Body != nil && *req.Body == "Edited"
+	}), mock.Anything).Return(&model.Question{ID: 1}, nil)
+
+	router := handler.InitRoutes()
+	req := withUser(httptest.NewRequest("POST", "/questions", strings.NewReader(`{"text":"How to use Go?\nDetails"}`)), testUserID)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	req = withUser(httptest.NewRequest("PATCH", "/questions/1", strings.NewReader(`{"text":"Edited"}`)), testUserID)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Неизвестные поля по-прежнему отклоняются
+	req = withUser(httptest.NewRequest("POST", "/questions", strings.NewReader(`{"text":"Text","user_id":"x"}`)), testUserID)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"user_id"`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateQuestion_Validation(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	cases := []map[string]string{
+		{"body": "Body without title"},
+		{"title": "   ", "body": "Body"},
+		{"title": "Title without body"},
+		{"title": strings.Repeat("я", model.MaxTitleLength+1), "body": "Body"},
+		{"title": "Title", "body": strings.Repeat("x", model.MaxBodyLength+1)},
+	}
+	for _, c := range cases {
+		body, _ := json.Marshal(c)
+		req := httptest.NewRequest("POST", "/questions", bytes.NewBuffer(body))
+		req = withUser(req, testUserID)
+		rr := httptest.NewRecorder()
+
+		handler.InitRoutes().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	}
+
+	// Ограничение длины считается в символах, а не в байтах
+	mockService.On("CreateQuestion", mock.Anything, mock.Anything).Return(&model.Question{ID: 1}, nil)
+	body, _ := json.Marshal(map[string]string{"title": strings.Repeat("я", model.MaxTitleLength), "body": "Body"})
+	req := httptest.NewRequest("POST", "/questions", bytes.NewBuffer(body))
+	req = withUser(req, testUserID)
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestUpdateQuestion_EmptyTitle(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"title": ""})
+
+	req := httptest.NewRequest("PATCH", "/questions/1", bytes.NewBuffer(body))
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetQuestion_Slug(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	question := &model.Question{ID: 1, Title: "How to use Go?", Slug: "how-to-use-go"}
+	mockService.On("GetQuestion", mock.Anything, 1, mock.AnythingOfType("model.GetQuestionOptions")).Return(question, nil)
+
+	router := handler.InitRoutes()
+
+	// Канонический slug
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1/how-to-use-go", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Устаревший slug перенаправляется с сохранением параметров
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1/old-title?include=answers", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/questions/1/how-to-use-go?include=answers", rr.Header().Get("Location"))
+
+	// Вложенные маршруты не перекрываются маршрутом со slug
+	mockService.On("GetQuestionRevisions", mock.Anything, 1).Return([]model.Revision{}, nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/1/revisions", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSimilarQuestions(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("SimilarQuestions", mock.Anything, "go modules", 0).
+		Return([]model.Question{{ID: 7, Title: "How do Go modules work?"}}, nil)
+
+	router := handler.InitRoutes()
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/similar?title=go+modules", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response []model.Question
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Len(t, response, 1)
+	assert.Equal(t, 7, response[0].ID)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/questions/similar", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestionRevisions_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedRevisions := []model.Revision{{
+		ID:         1,
+		EntityType: model.RevisionEntityQuestion,
+		EntityID:   1,
+		EditorID:   "user-123",
+		Changes:    map[string]model.FieldChange{"body": {Old: "Old", New: "New"}},
+	}}
+
+	mockService.On("GetQuestionRevisions", mock.Anything, 1).Return(expectedRevisions, nil)
+
+	req := httptest.NewRequest("GET", "/questions/1/revisions", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response []model.Revision
+	json.Unmarshal(rr.Body.Bytes(), &response)
+
+	assert.Len(t, response, 1)
+	assert.Equal(t, "user-123", response[0].EditorID)
+	assert.Equal(t, "New", response[0].Changes["body"].New)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreQuestion_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	moderator := auth.Identity{Subject: "moderator-1", Role: auth.RoleModerator}
+	mockService.On("RestoreQuestion", mock.Anything, 1, moderator).Return(nil)
+
+	req := httptest.NewRequest("POST", "/questions/1/restore", nil)
+	req = withRole(req, "moderator-1", auth.RoleModerator)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreAnswer_NotDeleted(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("RestoreAnswer", mock.Anything, 1, auth.Identity{Subject: "user-123", Role: auth.RoleUser}).Return(service.ErrNotFound)
+
+	req := httptest.NewRequest("POST", "/answers/1/restore", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAnswer_IncludeDeleted(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("GetAnswer", mock.Anything, 1, model.GetAnswerOptions{IncludeDeleted: true}).
+		Return(&model.Answer{ID: 1, Text: "Deleted answer"}, nil)
+
+	req := httptest.NewRequest("GET", "/answers/1?include_deleted=true", nil)
+	req = withRole(req, "moderator-1", auth.RoleModerator)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateAnswer_Unauthenticated(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"user_id": "user-123", "text": "Test answer"})
+
+	req := httptest.NewRequest("POST", "/questions/1/answers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	mockService.AssertNotCalled(t, "CreateAnswer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAnswer_Forbidden(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("DeleteAnswer", mock.Anything, 1, auth.Identity{Subject: "intruder", Role: auth.RoleUser}).Return(service.ErrForbidden)
+
+	req := httptest.NewRequest("DELETE", "/answers/1", nil)
+	req = withUser(req, "intruder")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAnswer_IncludeDeletedRequiresModerator(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest("GET", "/answers/1?include_deleted=true", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.AssertNotCalled(t, "GetAnswer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLockQuestion_InsufficientRole(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest("POST", "/questions/1/lock", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var response map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "insufficient_role", response["code"])
+
+	mockService.AssertNotCalled(t, "SetQuestionLocked", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLockQuestion_Moderator(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("SetQuestionLocked", mock.Anything, 1, true).Return(nil)
+
+	req := httptest.NewRequest("POST", "/questions/1/lock", nil)
+	req = withRole(req, "moderator-1", auth.RoleModerator)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAnswer_Locked(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("UpdateAnswer", mock.Anything, 1, mock.Anything, auth.Identity{Subject: "user-123", Role: auth.RoleUser}).
+		Return(nil, service.ErrLocked)
+
+	body, _ := json.Marshal(map[string]string{"text": "Updated answer"})
+
+	req := httptest.NewRequest("PATCH", "/answers/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var response map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "locked", response["code"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSetUserRole_Admin(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	admin := auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin}
+	mockService.On("SetUserRole", mock.Anything, "user-123", auth.RoleModerator, admin).
+		Return(&model.UserRole{UserID: "user-123", Role: "moderator", GrantedBy: "admin-1"}, nil)
+
+	body, _ := json.Marshal(map[string]string{"role": "moderator"})
+
+	req := httptest.NewRequest("PUT", "/users/user-123/role", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withRole(req, "admin-1", auth.RoleAdmin)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSetUserRole_RequiresAdmin(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"role": "admin"})
+
+	req := httptest.NewRequest("PUT", "/users/user-123/role", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withRole(req, "moderator-1", auth.RoleModerator)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserQuestions_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListQuestions", mock.Anything, model.QuestionListOptions{
+		Sort:   model.QuestionSortNewest,
+		UserID: "user-123",
+	}).Return(&model.QuestionPage{Items: []model.Question{{ID: 1, UserID: "user-123"}}}, nil)
+
+	req := httptest.NewRequest("GET", "/users/user-123/questions", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUserAnswers_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListUserAnswers", mock.Anything, "user-123", model.AnswerListOptions{Limit: 5, Sort: model.AnswerSortNewest}).
+		Return(&model.AnswerPage{Items: []model.Answer{{ID: 1, UserID: "user-123"}}}, nil)
+
+	req := httptest.NewRequest("GET", "/users/user-123/answers?limit=5&sort=newest", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.AnswerPage
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Len(t, response.Items, 1)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestVoteAnswer_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("VoteAnswer", mock.Anything, 1, -1, auth.Identity{Subject: "user-123", Role: auth.RoleUser}).
+		Return(&model.VoteResult{Score: 2, Value: -1}, nil)
+
+	body, _ := json.Marshal(map[string]int{"value": -1})
+
+	req := httptest.NewRequest("POST", "/answers/1/vote", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.VoteResult
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, 2, response.Score)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestVoteQuestion_InvalidValue(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	for _, body := range []string{`{"value": 5}`, `{}`} {
+		req := httptest.NewRequest("POST", "/questions/1/vote", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = withUser(req, "user-123")
+		rr := httptest.NewRecorder()
+
+		router := handler.InitRoutes()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+
+	mockService.AssertNotCalled(t, "VoteQuestion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAnswers_SortByScore(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListAnswers", mock.Anything, 1, model.AnswerListOptions{Sort: model.AnswerSortScore}).
+		Return(&model.AnswerPage{Items: []model.Answer{}}, nil)
+
+	req := httptest.NewRequest("GET", "/questions/1/answers?sort=score", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAcceptAnswer_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	answerID := 2
+	mockService.On("AcceptAnswer", mock.Anything, 1, 2, auth.Identity{Subject: "user-123", Role: auth.RoleUser}).
+		Return(&model.Question{ID: 1, UserID: "user-123", AcceptedAnswerID: &answerID}, nil)
+
+	req := httptest.NewRequest("POST", "/questions/1/accept/2", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.Question
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, 2, *response.AcceptedAnswerID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAcceptAnswer_Mismatch(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("AcceptAnswer", mock.Anything, 1, 9, mock.Anything).Return(nil, service.ErrAnswerMismatch)
+
+	req := httptest.NewRequest("POST", "/questions/1/accept/9", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuestions_UnansweredFilter(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListQuestions", mock.Anything, model.QuestionListOptions{
+		Sort:       model.QuestionSortNewest,
+		Unanswered: true,
+		Unaccepted: true,
+	}).Return(&model.QuestionPage{Items: []model.Question{}}, nil)
+
+	req := httptest.NewRequest("GET", "/questions?unanswered=true&unaccepted=true", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearch_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expectedPage := &model.SearchPage{Items: []model.SearchResult{{
+		Type:       model.SearchTypeAnswer,
+		ID:         3,
+		QuestionID: 1,
+		Rank:       0.6,
+		Snippet:    "create a <mark>GIN</mark> index",
+	}}}
+
+	mockService.On("Search", mock.Anything, "gin index", model.SearchOptions{
+		Query: "gin index",
+		Type:  model.SearchTypeAnswer,
+		Limit: 10,
+	}).Return(expectedPage, nil)
+
+	req := httptest.NewRequest("GET", "/search?q=gin+index&type=answer&limit=10", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response model.SearchPage
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, "create a <mark>GIN</mark> index", response.Items[0].Snippet)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearch_InvalidParams(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	for _, query := range []string{"", "?q=", "?q=go&type=comment", "?q=go&cursor=garbage"} {
+		req := httptest.NewRequest("GET", "/search"+query, nil)
+		rr := httptest.NewRecorder()
+
+		router := handler.InitRoutes()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+
+	mockService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetQuestions_TagFilter(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListQuestions", mock.Anything, model.QuestionListOptions{
+		Sort:     model.QuestionSortNewest,
+		Tags:     []string{"go", "postgres"},
+		TagMatch: model.TagMatchAny,
+	}).Return(&model.QuestionPage{Items: []model.Question{}}, nil)
+
+	req := httptest.NewRequest("GET", "/questions?tag=go&tag=postgres&tag_match=any", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateQuestion_InvalidTag(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("CreateQuestion", mock.Anything, mock.Anything).Return((*model.Question)(nil), service.ErrInvalidTag)
+
+	body, _ := json.Marshal(map[string]interface{}{"title": "Test question", "body": "Body", "tags": []string{"<b>"}})
+
+	req := httptest.NewRequest("POST", "/questions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetTags_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListTags", mock.Anything, model.TagListOptions{Prefix: "po", Limit: 5}).
+		Return([]model.Tag{{ID: 1, Name: "postgres", QuestionCount: 12}}, nil)
+
+	req := httptest.NewRequest("GET", "/tags?prefix=po&limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response []model.Tag
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, 12, response[0].QuestionCount)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRenameTag_Conflict(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("RenameTag", mock.Anything, "golang", "go").Return(nil, service.ErrTagExists)
+
+	body, _ := json.Marshal(map[string]string{"name": "go"})
+
+	req := httptest.NewRequest("PATCH", "/tags/golang", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withRole(req, "admin-1", auth.RoleAdmin)
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMergeTag_RequiresAdmin(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"into": "go"})
+
+	req := httptest.NewRequest("POST", "/tags/golang/merge", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+
+	router := handler.InitRoutes()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateQuestionComment_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	expected := &model.Comment{ID: 1, EntityType: model.CommentEntityQuestion, EntityID: 1, UserID: testUserID, Text: "Which Go version?"}
+	mockService.On("CreateQuestionComment", mock.Anything, 1, model.CreateCommentRequest{UserID: testUserID, Text: "Which Go version?"}).
+		Return(expected, nil)
+
+	body, _ := json.Marshal(map[string]string{"text": "Which Go version?"})
+	req := httptest.NewRequest("POST", "/questions/1/comments", bytes.NewBuffer(body))
+	req = withUser(req, testUserID)
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var response model.Comment
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "Which Go version?", response.Text)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateAnswerComment_Validation(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+	router := handler.InitRoutes()
+
+	for _, text := range []string{"", "   ", strings.Repeat("x", model.MaxCommentLength+1)} {
+		body, _ := json.Marshal(map[string]string{"text": text})
+		req := httptest.NewRequest("POST", "/answers/1/comments", bytes.NewBuffer(body))
+		req = withUser(req, "user-123")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	}
+
+	// Без токена комментировать нельзя
+	body, _ := json.Marshal(map[string]string{"text": "Nice"})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/answers/1/comments", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	mockService.AssertNotCalled(t, "CreateAnswerComment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAnswerComments_Pagination(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	cursor := model.Cursor{Sort: model.CommentSortOldest, CreatedAt: time.Now().UTC(), ID: 3}
+	mockService.On("ListAnswerComments", mock.Anything, 2, mock.MatchedBy(func(opts model.CommentListOptions) bool {
+		return opts.Limit == 10 && opts.After != nil && opts.After.ID == 3
+	})).Return(&model.CommentPage{Items: []model.Comment{{ID: 4}}}, nil)
+
+	req := httptest.NewRequest("GET", "/answers/2/comments?limit=10&cursor="+cursor.Encode(), nil)
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Курсор другой сортировки не принимается
+	other := model.Cursor{Sort: string(model.AnswerSortScore), ID: 3}
+	req = httptest.NewRequest("GET", "/answers/2/comments?cursor="+other.Encode(), nil)
+	rr = httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteComment_Forbidden(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	actor := auth.Identity{Subject: "user-123", Role: auth.RoleUser}
+	mockService.On("DeleteComment", mock.Anything, 5, actor).Return(service.ErrForbidden)
+
+	req := httptest.NewRequest("DELETE", "/comments/5", nil)
+	req = withUser(req, "user-123")
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// readEvent читает из потока SSE следующее событие, пропуская пинги
+func readEvent(t *testing.T, reader *bufio.Reader) (id, name, data string) {
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && id != "":
+			return id, name, data
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestQuestionEvents_StreamAndResume(t *testing.T) {
+	mockService := new(MockService)
+	broker := events.NewBroker(10)
+	handler := NewHandler(mockService, WithEvents(broker))
+
+	mockService.On("GetQuestion", mock.Anything, 1, model.GetQuestionOptions{}).Return(&model.Question{ID: 1}, nil)
+
+	server := httptest.NewServer(handler.InitRoutes())
+	defer server.Close()
+
+	broker.Publish(model.Event{ID: 11, Type: model.EventAnswerCreated, QuestionID: 1, AnswerID: 1})
+	broker.Publish(model.Event{ID: 12, Type: model.EventAnswerCreated, QuestionID: 2, AnswerID: 2})
+	broker.Publish(model.Event{ID: 14, Type: model.EventAnswerDeleted, QuestionID: 1, AnswerID: 1})
+
+	req, _ := http.NewRequest("GET", server.URL+"/questions/1/events", nil)
+	// ID событий - ID записей журнала, в них бывают пропуски
+	req.Header.Set("Last-Event-ID", "11")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	// Повтор пропущенных событий этого вопроса
+	id, name, _ := readEvent(t, reader)
+	assert.Equal(t, "14", id)
+	assert.Equal(t, model.EventAnswerDeleted, name)
+
+	// Новые события приходят сразу
+	broker.Publish(model.Event{ID: 15, Type: model.EventQuestionUpdated, QuestionID: 2})
+	broker.Publish(model.Event{ID: 17, Type: model.EventQuestionUpdated, QuestionID: 1})
+	id, name, data := readEvent(t, reader)
+	assert.Equal(t, "17", id)
+	assert.Equal(t, model.EventQuestionUpdated, name)
+
+	var event model.Event
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	assert.Equal(t, 1, event.QuestionID)
+}
+
+func TestEvents_Unavailable(t *testing.T) {
+	handler := NewHandler(new(MockService))
+
+	rr := httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, httptest.NewRequest("GET", "/events", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	handler = NewHandler(new(MockService), WithEvents(events.NewBroker(10)))
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	rr = httptest.NewRecorder()
+	handler.InitRoutes().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func dialWS(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestWebSocket_Subscriptions(t *testing.T) {
+	mockService := new(MockService)
+	broker := events.NewBroker(10)
+	handler := NewHandler(mockService, WithEvents(broker))
+	server := httptest.NewServer(handler.InitRoutes())
+	defer server.Close()
+
+	// Теги подписки приводятся к основным тегам, как в фильтре списка вопросов
+	mockService.On("ResolveTagNames", mock.Anything, []string{"Golang"}).Return([]string{"go"}, nil)
+
+	conn := dialWS(t, server)
+	defer conn.Close()
+
+	var msg wsMessage
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Questions: []int{1}, Tags: []string{"Golang"}}))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "subscriptions", msg.Type)
+	assert.Equal(t, []int{1}, msg.Questions)
+	assert.Equal(t, []string{"go"}, msg.Tags)
+
+	broker.Publish(model.Event{ID: 1, Type: model.EventAnswerCreated, QuestionID: 2})
+	broker.Publish(model.Event{ID: 2, Type: model.EventAnswerCreated, QuestionID: 1, AnswerID: 5})
+	broker.Publish(model.Event{ID: 3, Type: model.EventQuestionCreated, QuestionID: 3, Tags: []string{"go"}})
+
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "event", msg.Type)
+	assert.Equal(t, 5, msg.Event.AnswerID)
+
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, model.EventQuestionCreated, msg.Event.Type)
+	assert.Equal(t, 3, msg.Event.QuestionID)
+
+	// После отписки события вопроса не приходят
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "unsubscribe", Questions: []int{1}}))
+	msg = wsMessage{}
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "subscriptions", msg.Type)
+	assert.Empty(t, msg.Questions)
+
+	broker.Publish(model.Event{ID: 4, Type: model.EventAnswerDeleted, QuestionID: 1})
+	broker.Publish(model.Event{ID: 5, Type: model.EventAnswerDeleted, QuestionID: 4, Tags: []string{"go"}})
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, 4, msg.Event.QuestionID)
+
+	// Ошибочные команды не разрывают соединение
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "error", msg.Type)
+
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "jump"}))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "Unknown action", msg.Error)
+}
+
+func TestWebSocket_InvalidTag(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, WithEvents(events.NewBroker(10)))
+	server := httptest.NewServer(handler.InitRoutes())
+	defer server.Close()
+
+	mockService.On("ResolveTagNames", mock.Anything, []string{"c++!"}).Return(nil, service.ErrInvalidTag)
+
+	conn := dialWS(t, server)
+	defer conn.Close()
+
+	var msg wsMessage
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Questions: []int{1}, Tags: []string{"c++!"}}))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, "Invalid tag", msg.Error)
+
+	// Команда с ошибкой не меняет подписки
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Questions: []int{2}}))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, []int{2}, msg.Questions)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWebSocket_SlowClientDropped(t *testing.T) {
+	broker := events.NewBroker(10)
+	handler := NewHandler(new(MockService), WithEvents(broker))
+	server := httptest.NewServer(handler.InitRoutes())
+	defer server.Close()
+
+	conn := dialWS(t, server)
+	defer conn.Close()
+
+	var msg wsMessage
+	require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Questions: []int{1}}))
+	require.NoError(t, conn.ReadJSON(&msg))
+
+	// Клиент не читает; публикация не блокируется, а соединение в итоге закрывается
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 100000; i++ {
+			broker.Publish(model.Event{ID: uint64(i), Type: model.EventAnswerCreated, QuestionID: 1, Data: strings.Repeat("x", 512)})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a slow WebSocket client")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var closeErr *websocket.CloseError
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			require.ErrorAs(t, err, &closeErr)
+			break
+		}
+	}
+	assert.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+}
+
+func TestWSSubscriptions_Limit(t *testing.T) {
+	subs := newWSSubscriptions()
+	ids := make([]int, wsMaxSubscriptions+1)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	_, err := subs.apply(wsRequest{Action: "subscribe", Questions: ids})
+	assert.Error(t, err)
+	assert.False(t, subs.matches(model.Event{QuestionID: 1}))
+}
+
+func TestWSSubscriptions_LimitCountsNewEntries(t *testing.T) {
+	subs := newWSSubscriptions()
+	ids := make([]int, wsMaxSubscriptions)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	_, err := subs.apply(wsRequest{Action: "subscribe", Questions: ids})
+	require.NoError(t, err)
+
+	// Повторная подписка на те же вопросы и дубликаты в команде не превышают лимит
+	_, err = subs.apply(wsRequest{Action: "subscribe", Questions: append(ids, 1, 1)})
+	assert.NoError(t, err)
+
+	_, err = subs.apply(wsRequest{Action: "subscribe", Questions: []int{wsMaxSubscriptions + 1}})
+	assert.Error(t, err)
+}
+
+func TestCreateWebhook_Success(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	admin := auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin}
+	req := model.CreateWebhookRequest{URL: "https://example.com/hooks", Events: []string{model.EventAnswerCreated}, Secret: "0123456789abcdef"}
+	mockService.On("CreateWebhook", mock.Anything, req, admin).
+		Return(&model.Webhook{ID: 1, URL: req.URL, Events: req.Events, Secret: req.Secret, CreatedBy: "admin-1"}, nil)
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest("POST", "/webhooks", bytes.NewBuffer(body))
+	httpReq = withRole(httpReq, "admin-1", auth.RoleAdmin)
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, httpReq)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	// Секрет не возвращается в ответе
+	assert.NotContains(t, rr.Body.String(), req.Secret)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateWebhook_Errors(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+	router := handler.InitRoutes()
+
+	mockService.On("CreateWebhook", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: secret too short", service.ErrInvalidWebhook))
+
+	body, _ := json.Marshal(map[string]string{"url": "ftp://example.com", "secret": "0123456789abcdef"})
+	req := httptest.NewRequest("POST", "/webhooks", bytes.NewBuffer(body))
+	req = withRole(req, "admin-1", auth.RoleAdmin)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Регистрировать вебхуки может только администратор
+	req = httptest.NewRequest("POST", "/webhooks", bytes.NewBuffer(body))
+	req = withRole(req, "moderator-1", auth.RoleModerator)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.AssertNumberOfCalls(t, "CreateWebhook", 1)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+	router := handler.InitRoutes()
+
+	cursor := model.Cursor{Sort: model.DeliverySortNewest, CreatedAt: time.Now().UTC(), ID: 9}
+	mockService.On("ListWebhookDeliveries", mock.Anything, 1, mock.MatchedBy(func(opts model.DeliveryListOptions) bool {
+		return opts.Limit == 5 && opts.Status == model.DeliveryFailed && opts.After != nil && opts.After.ID == 9
+	})).Return(&model.DeliveryPage{Items: []model.WebhookDelivery{{ID: 8, Status: model.DeliveryFailed}}}, nil)
+
+	req := httptest.NewRequest("GET", "/webhooks/1/deliveries?limit=5&status=failed&cursor="+cursor.Encode(), nil)
+	req = withRole(req, "admin-1", auth.RoleAdmin)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var page model.DeliveryPage
+	json.Unmarshal(rr.Body.Bytes(), &page)
+	assert.Len(t, page.Items, 1)
+
+	req = httptest.NewRequest("GET", "/webhooks/1/deliveries?status=lost", nil)
+	req = withRole(req, "admin-1", auth.RoleAdmin)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateAnswer_QuestionNotFound(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("CreateAnswer", mock.Anything, 999, model.CreateAnswerRequest{UserID: testUserID, Text: "Answer"}).
+		Return((*model.Answer)(nil), service.ErrNotFound)
+
+	body, _ := json.Marshal(map[string]string{"text": "Answer"})
+	req := httptest.NewRequest("POST", "/questions/999/answers", bytes.NewBuffer(body))
+	req = withUser(req, testUserID)
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	var response problem.Problem
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "not_found", response.Code)
+	assert.Equal(t, "Question not found", response.Detail)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateQuestion_FieldErrors(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	body, _ := json.Marshal(map[string]string{"title": " ", "body": strings.Repeat("x", model.MaxBodyLength+1)})
+	req := httptest.NewRequest("POST", "/questions", bytes.NewBuffer(body))
+	req = withUser(req, testUserID)
+	rr := httptest.NewRecorder()
+
+	handler.InitRoutes().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var response problem.Problem
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, "validation_failed", response.Code)
+	require.Len(t, response.Errors, 2)
+	assert.Equal(t, "title", response.Errors[0].Field)
+	assert.Equal(t, "body", response.Errors[1].Field)
+
+	mockService.AssertNotCalled(t, "CreateQuestion", mock.Anything, mock.Anything)
+}
+
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{service.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{service.ErrLocked, http.StatusForbidden, "locked"},
+		{fmt.Errorf("%w: %q", service.ErrTagNotFound, "go"), http.StatusNotFound, "tag_not_found"},
+		{service.ErrTagExists, http.StatusConflict, "tag_exists"},
+		{service.ErrConflict, http.StatusConflict, "conflict"},
+		{service.ErrInvalidWebhook.WithField("secret", "is too short"), http.StatusBadRequest, "invalid_webhook"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		writeServiceError(rr, httptest.NewRequest("GET", "/", nil), tt.err, "Not found")
+
+		var response problem.Problem
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.Equal(t, tt.status, rr.Code, tt.err.Error())
+		assert.Equal(t, tt.code, response.Code, tt.err.Error())
+	}
+
+	rr := httptest.NewRecorder()
+	writeServiceError(rr, httptest.NewRequest("GET", "/", nil), service.ErrInvalidWebhook.WithField("secret", "is too short"), "Not found")
+	var response problem.Problem
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.Equal(t, []model.FieldError{{Field: "secret", Message: "is too short"}}, response.Errors)
+}
+
+func TestRequestValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		body   string
+		status int
+		field  string
+		msg    string
+	}{
+		{"unknown field", "POST", "/questions", testUserID,
+			`{"title":"Title","body":"Body","user_id":"someone-else"}`, http.StatusBadRequest, "user_id", "is not allowed"},
+		{"blank answer", "POST", "/questions/1/answers", testUserID,
+			`{"text":" \n\t"}`, http.StatusBadRequest, "text", "must not be blank"},
+		{"too many tags", "POST", "/questions", testUserID,
+			`{"title":"Title","body":"Body","tags":["a","b","c","d","e","f"]}`, http.StatusBadRequest, "tags", "must contain at most 5 items"},
+		{"blank update", "PATCH", "/answers/1", testUserID,
+			`{"text":"   "}`, http.StatusBadRequest, "text", "must not be blank"},
+		{"wrong type", "POST", "/answers/1/vote", testUserID,
+			`{"value":"up"}`, http.StatusBadRequest, "value", "must be of type int"},
+		{"vote out of range", "POST", "/answers/1/vote", testUserID,
+			`{"value":2}`, http.StatusBadRequest, "value", "must be one of -1, 0, 1"},
+		{"long comment", "POST", "/questions/1/comments", testUserID,
+			`{"text":"` + strings.Repeat("я", model.MaxCommentLength+1) + `"}`, http.StatusBadRequest, "text", "must be at most 600 characters"},
+		{"body too large", "POST", "/questions", testUserID,
+			`{"title":"Title","body":"` + strings.Repeat("x", 2<<20) + `"}`, http.StatusRequestEntityTooLarge, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			handler := NewHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = withUser(req, tt.user)
+			rr := httptest.NewRecorder()
+
+			handler.InitRoutes().ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+
+			var response problem.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			if tt.field != "" {
+				require.Len(t, response.Errors, 1)
+				assert.Equal(t, model.FieldError{Field: tt.field, Message: tt.msg}, response.Errors[0])
+			}
+
+			// До сервиса некорректный запрос не доходит
+			assert.Empty(t, mockService.Calls)
+		})
+	}
 }
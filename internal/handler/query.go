@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"qna-api/internal/model"
+)
+
+// parseQuestionListOptions разбирает параметры запроса GET /questions
+func parseQuestionListOptions(r *http.Request) (model.QuestionListOptions, error) {
+	q := r.URL.Query()
+	var opts model.QuestionListOptions
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	opts.Sort = model.QuestionSort(q.Get("sort"))
+	if opts.Sort == "" {
+		opts.Sort = model.QuestionSortNewest
+	}
+	if !opts.Sort.Valid() {
+		return opts, errors.New("Invalid sort parameter")
+	}
+
+	if opts.After, err = parseCursor(q.Get("cursor"), string(opts.Sort)); err != nil {
+		return opts, err
+	}
+
+	if opts.CreatedAfter, err = parseTime(q.Get("created_after")); err != nil {
+		return opts, errors.New("Invalid created_after parameter")
+	}
+	if opts.CreatedBefore, err = parseTime(q.Get("created_before")); err != nil {
+		return opts, errors.New("Invalid created_before parameter")
+	}
+	opts.Tags = q["tag"]
+	opts.TagMatch = model.TagMatch(q.Get("tag_match"))
+	if opts.TagMatch != "" && opts.TagMatch != model.TagMatchAll && opts.TagMatch != model.TagMatchAny {
+		return opts, errors.New("Invalid tag_match parameter")
+	}
+
+	if opts.Unanswered, err = parseBool(q.Get("unanswered")); err != nil {
+		return opts, errors.New("Invalid unanswered parameter")
+	}
+	if opts.Unaccepted, err = parseBool(q.Get("unaccepted")); err != nil {
+		return opts, errors.New("Invalid unaccepted parameter")
+	}
+	if opts.IncludeDeleted, err = parseBool(q.Get("include_deleted")); err != nil {
+		return opts, errors.New("Invalid include_deleted parameter")
+	}
+
+	return opts, nil
+}
+
+// parseAnswerListOptions разбирает параметры запроса GET /questions/{id}/answers
+func parseAnswerListOptions(r *http.Request) (model.AnswerListOptions, error) {
+	q := r.URL.Query()
+	var opts model.AnswerListOptions
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	opts.Sort = model.AnswerSort(q.Get("sort"))
+	if opts.Sort == "" {
+		opts.Sort = model.AnswerSortOldest
+	}
+	if !opts.Sort.Valid() {
+		return opts, errors.New("Invalid sort parameter")
+	}
+
+	if opts.After, err = parseCursor(q.Get("cursor"), string(opts.Sort)); err != nil {
+		return opts, err
+	}
+	if opts.IncludeDeleted, err = parseBool(q.Get("include_deleted")); err != nil {
+		return opts, errors.New("Invalid include_deleted parameter")
+	}
+
+	return opts, nil
+}
+
+// parseCommentListOptions разбирает параметры запросов GET /questions/{id}/comments и /answers/{id}/comments
+func parseCommentListOptions(r *http.Request) (model.CommentListOptions, error) {
+	q := r.URL.Query()
+	var opts model.CommentListOptions
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	if opts.After, err = parseCursor(q.Get("cursor"), model.CommentSortOldest); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// parseDeliveryListOptions разбирает параметры запроса GET /webhooks/{id}/deliveries
+func parseDeliveryListOptions(r *http.Request) (model.DeliveryListOptions, error) {
+	q := r.URL.Query()
+	var opts model.DeliveryListOptions
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	if opts.After, err = parseCursor(q.Get("cursor"), model.DeliverySortNewest); err != nil {
+		return opts, err
+	}
+
+	switch opts.Status = q.Get("status"); opts.Status {
+	case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryFailed:
+	default:
+		return opts, errors.New("Invalid status parameter")
+	}
+
+	return opts, nil
+}
+
+// parseGetQuestionOptions разбирает параметры запроса GET /questions/{id}.
+// Ответы включаются по умолчанию, как до появления include; include=none их отключает.
+func parseGetQuestionOptions(r *http.Request) (model.GetQuestionOptions, error) {
+	q := r.URL.Query()
+	opts := model.GetQuestionOptions{IncludeAnswers: true}
+
+	var answers, none bool
+	for _, include := range strings.Split(q.Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "answers":
+			answers = true
+		case "none":
+			none = true
+		default:
+			return opts, errors.New("Invalid include parameter")
+		}
+	}
+	if none {
+		if answers {
+			return opts, errors.New("Invalid include parameter")
+		}
+		opts.IncludeAnswers = false
+	}
+
+	limit, err := parseLimit(q.Get("answers_limit"))
+	if err != nil {
+		return opts, errors.New("Invalid answers_limit parameter")
+	}
+	opts.AnswersLimit = limit
+
+	// Порядок включенных ответов задается так же, как sort в GET /questions/{id}/answers
+	opts.AnswersSort = model.AnswerSort(q.Get("answers_sort"))
+	if opts.AnswersSort == "" {
+		opts.AnswersSort = model.AnswerSortOldest
+	}
+	if !opts.AnswersSort.Valid() {
+		return opts, errors.New("Invalid answers_sort parameter")
+	}
+
+	if opts.IncludeDeleted, err = parseBool(q.Get("include_deleted")); err != nil {
+		return opts, errors.New("Invalid include_deleted parameter")
+	}
+
+	return opts, nil
+}
+
+// parseGetAnswerOptions разбирает параметры запроса GET /answers/{id}
+func parseGetAnswerOptions(r *http.Request) (model.GetAnswerOptions, error) {
+	var opts model.GetAnswerOptions
+
+	includeDeleted, err := parseBool(r.URL.Query().Get("include_deleted"))
+	if err != nil {
+		return opts, errors.New("Invalid include_deleted parameter")
+	}
+	opts.IncludeDeleted = includeDeleted
+
+	return opts, nil
+}
+
+// parseSearchOptions разбирает параметры запроса GET /search
+func parseSearchOptions(r *http.Request) (model.SearchOptions, error) {
+	q := r.URL.Query()
+	var opts model.SearchOptions
+
+	opts.Query = strings.TrimSpace(q.Get("q"))
+	if opts.Query == "" {
+		return opts, errors.New("Search query is required")
+	}
+
+	switch t := q.Get("type"); t {
+	case "", "all":
+		opts.Type = model.SearchTypeAll
+	case model.SearchTypeQuestion, model.SearchTypeAnswer:
+		opts.Type = t
+	default:
+		return opts, errors.New("Invalid type parameter")
+	}
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	if opts.After, err = parseCursor(q.Get("cursor"), model.SearchSortRank); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// parseCursor декодирует курсор и проверяет, что он выдан для той же сортировки
+func parseCursor(raw, sort string) (*model.Cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	cursor, err := model.DecodeCursor(raw)
+	if err != nil || cursor.Sort != sort {
+		return nil, errors.New("Invalid cursor")
+	}
+	return cursor, nil
+}
+
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > model.MaxPageLimit {
+		return 0, errors.New("Invalid limit parameter")
+	}
+	return limit, nil
+}
+
+func parseBool(raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+func parseTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
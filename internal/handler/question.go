@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"qna-api/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+// GetQuestions - получить страницу вопросов
+func (h *Handler) GetQuestions(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeJSON(w, http.StatusOK, model.QuestionPage{Items: []model.Question{}})
+		return
+	}
+
+	opts, err := parseQuestionListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	page, err := h.service.ListQuestions(r.Context(), opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// CreateQuestion - создать вопрос
+func (h *Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	req := model.CreateQuestionRequest{UserID: currentActor(r).Subject}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	question, err := h.service.CreateQuestion(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, question)
+}
+
+// GetSimilarQuestions - найти вопросы с похожим заголовком перед публикацией нового
+func (h *Handler) GetSimilarQuestions(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeJSON(w, http.StatusOK, []model.Question{})
+		return
+	}
+
+	title := strings.TrimSpace(r.URL.Query().Get("title"))
+	if title == "" {
+		writeError(w, http.StatusBadRequest, "Title parameter is required")
+		return
+	}
+
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	questions, err := h.service.SimilarQuestions(r.Context(), title, limit)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, questions)
+}
+
+// GetQuestion - получить вопрос по ID
+func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	opts, err := parseGetQuestionOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	question, err := h.service.GetQuestion(r.Context(), id, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	// Устаревший или неверный slug перенаправляется на канонический URL
+	if slug, ok := mux.Vars(r)["slug"]; ok && slug != question.Slug {
+		target := questionURL(question)
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, question)
+}
+
+// questionURL возвращает канонический URL вопроса со slug
+func questionURL(question *model.Question) string {
+	if question.Slug == "" {
+		return fmt.Sprintf("/questions/%d", question.ID)
+	}
+	return fmt.Sprintf("/questions/%d/%s", question.ID, question.Slug)
+}
+
+// UpdateQuestion - изменить вопрос
+func (h *Handler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	var req model.UpdateQuestionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	question, err := h.service.UpdateQuestion(r.Context(), id, req, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, question)
+}
+
+// GetQuestionRevisions - получить историю правок вопроса
+func (h *Handler) GetQuestionRevisions(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	revisions, err := h.service.GetQuestionRevisions(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+// AcceptAnswer - отметить ответ принятым
+func (h *Handler) AcceptAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	answerID, err := getIntVar(r, "answerId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	question, err := h.service.AcceptAnswer(r.Context(), id, answerID, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Question or answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, question)
+}
+
+// UnacceptAnswer - снять отметку о принятом ответе
+func (h *Handler) UnacceptAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	question, err := h.service.UnacceptAnswer(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, question)
+}
+
+// DeleteQuestion - удалить вопрос
+func (h *Handler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	err = h.service.DeleteQuestion(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Question deleted successfully"})
+}
+
+// RestoreQuestion - восстановить удаленный вопрос
+func (h *Handler) RestoreQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	err = h.service.RestoreQuestion(r.Context(), id, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Deleted question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Question restored successfully"})
+}
+
+// LockQuestion - заблокировать вопрос
+func (h *Handler) LockQuestion(w http.ResponseWriter, r *http.Request) {
+	h.setQuestionLocked(w, r, true)
+}
+
+// UnlockQuestion - снять блокировку вопроса
+func (h *Handler) UnlockQuestion(w http.ResponseWriter, r *http.Request) {
+	h.setQuestionLocked(w, r, false)
+}
+
+func (h *Handler) setQuestionLocked(w http.ResponseWriter, r *http.Request, locked bool) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	if err := h.service.SetQuestionLocked(r.Context(), id, locked); err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"locked": locked})
+}
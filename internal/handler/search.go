@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// Search - полнотекстовый поиск по вопросам и ответам
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeJSON(w, http.StatusOK, model.SearchPage{Items: []model.SearchResult{}})
+		return
+	}
+
+	opts, err := parseSearchOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.Search(r.Context(), opts.Query, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"qna-api/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTags - получить популярные теги с числом вопросов
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeJSON(w, http.StatusOK, []model.Tag{})
+		return
+	}
+
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, err := h.service.ListTags(r.Context(), model.TagListOptions{
+		Prefix: strings.TrimSpace(r.URL.Query().Get("prefix")),
+		Limit:  limit,
+	})
+	if err != nil {
+		writeServiceError(w, r, err, "Tag not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// RenameTag - переименовать тег
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	var req model.RenameTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.RenameTag(r.Context(), mux.Vars(r)["name"], req.Name)
+	if err != nil {
+		writeServiceError(w, r, err, "Tag not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tag)
+}
+
+// MergeTag - объединить тег с другим тегом
+func (h *Handler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	var req model.MergeTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag, err := h.service.MergeTags(r.Context(), mux.Vars(r)["name"], req.Into)
+	if err != nil {
+		writeServiceError(w, r, err, "Tag not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tag)
+}
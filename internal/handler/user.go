@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+// GetUserQuestions - получить страницу вопросов пользователя
+func (h *Handler) GetUserQuestions(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	opts, err := parseQuestionListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.UserID = mux.Vars(r)["id"]
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	page, err := h.service.ListQuestions(r.Context(), opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GetUserAnswers - получить страницу ответов пользователя
+func (h *Handler) GetUserAnswers(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	opts, err := parseAnswerListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludeDeleted && !requireModerator(w, r) {
+		return
+	}
+
+	page, err := h.service.ListUserAnswers(r.Context(), mux.Vars(r)["id"], opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GetUserRole - получить роль пользователя
+func (h *Handler) GetUserRole(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	userID := mux.Vars(r)["id"]
+
+	role, err := h.service.GetUserRole(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, r, err, "User not found")
+		return
+	}
+	if role == "" {
+		role = string(auth.RoleUser)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"user_id": userID, "role": role})
+}
+
+// SetUserRole - назначить роль пользователю
+func (h *Handler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	userID := mux.Vars(r)["id"]
+
+	var req model.SetUserRoleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	role, ok := auth.ParseRole(req.Role)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Role must be one of user, moderator, admin")
+		return
+	}
+
+	userRole, err := h.service.SetUserRole(r.Context(), userID, role, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "User not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userRole)
+}
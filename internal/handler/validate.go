@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"qna-api/internal/model"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// maxRequestBodySize - предельный размер тела запроса в байтах
+const maxRequestBodySize = 1 << 20
+
+// validate проверяет запросы по тегам validate; поля называются по json-тегам
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(fieldName)
+	// notblank отклоняет строки только из пробелов
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	return v
+}
+
+// fieldName возвращает имя поля в API: json-тег, а для полей вне тела запроса - snake_case
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name != "" && name != "-" {
+		return name
+	}
+
+	var b strings.Builder
+	runes := []rune(field.Name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// decodeJSON читает тело запроса в dst и проверяет его по тегам validate.
+// Неизвестные поля и слишком большое тело отклоняются; при ошибке ответ уже записан.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		writeDecodeError(w, err)
+		return false
+	}
+
+	if fields := validateRequest(dst); len(fields) > 0 {
+		writeValidationError(w, fields)
+		return false
+	}
+	return true
+}
+
+// writeDecodeError пишет ответ на ошибку разбора тела запроса
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		writeValidationError(w, []model.FieldError{{Field: typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type)}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeValidationError(w, []model.FieldError{{Field: field, Message: "is not allowed"}})
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+	}
+}
+
+// validateRequest проверяет структуру по тегам validate и возвращает ошибки полей
+func validateRequest(req interface{}) []model.FieldError {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []model.FieldError{{Field: "", Message: err.Error()}}
+	}
+
+	fields := make([]model.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, model.FieldError{Field: fe.Field(), Message: fieldMessage(fe)})
+	}
+	return fields
+}
+
+// fieldMessage описывает нарушенное правило для клиента
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "url":
+		return "must be a valid URL"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// VoteQuestion - проголосовать за вопрос
+func (h *Handler) VoteQuestion(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	value, ok := decodeVote(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.VoteQuestion(r.Context(), id, value, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Question not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// VoteAnswer - проголосовать за ответ
+func (h *Handler) VoteAnswer(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	value, ok := decodeVote(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.VoteAnswer(r.Context(), id, value, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Answer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// decodeVote читает значение голоса из тела запроса и пишет 400, если оно некорректно
+func decodeVote(w http.ResponseWriter, r *http.Request) (int, bool) {
+	var req model.VoteRequest
+	if !decodeJSON(w, r, &req) {
+		return 0, false
+	}
+
+	return *req.Value, true
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// GetWebhooks - получить список вебхуков
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err, "Webhook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// CreateWebhook - зарегистрировать вебхук
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	var req model.CreateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), req, currentActor(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Webhook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+// DeleteWebhook - удалить вебхук и историю его доставок
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		writeServiceError(w, r, err, "Webhook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries - получить историю доставок вебхука
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, http.StatusServiceUnavailable, "Service not available")
+		return
+	}
+
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	opts, err := parseDeliveryListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListWebhookDeliveries(r.Context(), id, opts)
+	if err != nil {
+		writeServiceError(w, r, err, "Webhook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
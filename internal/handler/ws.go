@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"qna-api/internal/model"
+	"qna-api/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+// Параметры соединений WebSocket
+var (
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 60 * time.Second
+	wsWriteWait    = 10 * time.Second
+)
+
+const (
+	wsMaxMessageSize   = 4096
+	wsMaxSubscriptions = 100
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsRequest - команда клиента: subscribe или unsubscribe
+type wsRequest struct {
+	Action    string   `json:"action"`
+	Questions []int    `json:"questions"`
+	Tags      []string `json:"tags"`
+}
+
+// wsMessage - сообщение сервера: event, subscriptions или error
+type wsMessage struct {
+	Type      string       `json:"type"`
+	Event     *model.Event `json:"event,omitempty"`
+	Questions []int        `json:"questions,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// wsSubscriptions - вопросы и теги, на которые подписано соединение
+type wsSubscriptions struct {
+	mu        sync.RWMutex
+	questions map[int]struct{}
+	tags      map[string]struct{}
+}
+
+func newWSSubscriptions() *wsSubscriptions {
+	return &wsSubscriptions{questions: map[int]struct{}{}, tags: map[string]struct{}{}}
+}
+
+// matches пропускает события подписанных вопросов и вопросов с подписанными тегами
+func (s *wsSubscriptions) matches(event model.Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.questions[event.QuestionID]; ok {
+		return true
+	}
+	for _, tag := range event.Tags {
+		if _, ok := s.tags[tag]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply выполняет команду клиента и возвращает итоговый набор подписок.
+// Теги в req уже приведены к именам основных тегов.
+func (s *wsSubscriptions) apply(req wsRequest) (wsMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Action {
+	case "subscribe":
+		// Повторные подписки в команде и уже действующие подписки в лимит не засчитываются
+		newQuestions := map[int]struct{}{}
+		for _, id := range req.Questions {
+			if _, ok := s.questions[id]; !ok {
+				newQuestions[id] = struct{}{}
+			}
+		}
+		newTags := map[string]struct{}{}
+		for _, tag := range req.Tags {
+			if _, ok := s.tags[tag]; !ok {
+				newTags[tag] = struct{}{}
+			}
+		}
+		if len(s.questions)+len(s.tags)+len(newQuestions)+len(newTags) > wsMaxSubscriptions {
+			return wsMessage{}, errors.New("Too many subscriptions")
+		}
+		for _, id := range req.Questions {
+			s.questions[id] = struct{}{}
+		}
+		for _, tag := range req.Tags {
+			s.tags[tag] = struct{}{}
+		}
+	case "unsubscribe":
+		for _, id := range req.Questions {
+			delete(s.questions, id)
+		}
+		for _, tag := range req.Tags {
+			delete(s.tags, tag)
+		}
+	default:
+		return wsMessage{}, errors.New("Unknown action")
+	}
+
+	reply := wsMessage{Type: "subscriptions", Questions: []int{}, Tags: []string{}}
+	for id := range s.questions {
+		reply.Questions = append(reply.Questions, id)
+	}
+	for tag := range s.tags {
+		reply.Tags = append(reply.Tags, tag)
+	}
+	return reply, nil
+}
+
+// WebSocket - соединение с подпиской на события вопросов и тегов
+func (h *Handler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	if h.events == nil {
+		writeError(w, http.StatusServiceUnavailable, "Event stream not available")
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade уже ответил клиенту ошибкой
+		return
+	}
+	defer conn.Close()
+
+	subs := newWSSubscriptions()
+	sub, _ := h.events.Subscribe(subs.matches, 0)
+	defer sub.Close()
+
+	replies := make(chan wsMessage, 8)
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.readWS(r.Context(), conn, subs, replies, stop)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		var msg wsMessage
+		select {
+		case reply, ok := <-replies:
+			if !ok {
+				return
+			}
+			msg = reply
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Брокер отключил соединение, которое не успевает принимать события, или сервер останавливается
+				closeMessage := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+				if h.events.Closed() {
+					closeMessage = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+				}
+				conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(wsWriteWait))
+				return
+			}
+			msg = wsMessage{Type: "event", Event: &event}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+			continue
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+// resolveWSTags приводит теги команды к именам основных тегов, как фильтр по тегам в списке вопросов,
+// чтобы подписка на синоним получала события вопросов с основным тегом
+func (h *Handler) resolveWSTags(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	tags, err := h.service.ResolveTagNames(ctx, names)
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		return nil, errors.New("Invalid tag")
+	case err != nil:
+		slog.ErrorContext(ctx, "Resolving WebSocket subscription tags failed", "error", err)
+		return nil, errors.New("Internal server error")
+	}
+	return tags, nil
+}
+
+// readWS читает команды клиента и передает ответы писателю; закрывает replies при разрыве
+func (h *Handler) readWS(ctx context.Context, conn *websocket.Conn, subs *wsSubscriptions, replies chan<- wsMessage, stop <-chan struct{}) {
+	defer close(replies)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var reply wsMessage
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			reply = wsMessage{Type: "error", Error: "Invalid message"}
+		} else if req.Tags, err = h.resolveWSTags(ctx, req.Tags); err != nil {
+			reply = wsMessage{Type: "error", Error: err.Error()}
+		} else if reply, err = subs.apply(req); err != nil {
+			reply = wsMessage{Type: "error", Error: err.Error()}
+		}
+
+		select {
+		case replies <- reply:
+		case <-stop:
+			return
+		}
+	}
+}
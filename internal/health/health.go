@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Статусы проверок и отчета
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check - проверка зависимости. Run возвращает подробности для подробного отчета
+// и ошибку, если зависимость недоступна.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (interface{}, error)
+}
+
+// Result - результат одной проверки
+type Result struct {
+	Name      string      `json:"name"`
+	Status    string      `json:"status"`
+	LatencyMS float64     `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Report - итог всех проверок; Status равен ok, только если прошли все проверки
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// OK сообщает, прошли ли все проверки
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker выполняет проверки параллельно, ограничивая каждую таймаутом
+type Checker struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// NewChecker создает набор проверок с таймаутом timeout на каждую
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{timeout: timeout, checks: checks}
+}
+
+// Run выполняет все проверки; результаты идут в порядке регистрации проверок
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run выполняет одну проверку; проверка, не уложившаяся в таймаут, считается неудачной
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		details interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.Run(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   out.details,
+	}
+	if out.err != nil {
+		result.Status = StatusFail
+		result.Error = out.err.Error()
+		if errors.Is(out.err, context.DeadlineExceeded) {
+			result.Error = "timed out"
+		}
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticCheck(name string, details interface{}, err error) Check {
+	return Check{Name: name, Run: func(ctx context.Context) (interface{}, error) { return details, err }}
+}
+
+func TestChecker_AllPass(t *testing.T) {
+	checker := NewChecker(time.Second,
+		staticCheck("database", PoolStats{Open: 2}, nil),
+		staticCheck("migrations", MigrationStatus{}, nil))
+
+	report := checker.Run(context.Background())
+
+	assert.True(t, report.OK())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, "migrations", report.Checks[1].Name)
+	assert.Equal(t, PoolStats{Open: 2}, report.Checks[0].Details)
+	assert.GreaterOrEqual(t, report.Checks[0].LatencyMS, 0.0)
+}
+
+func TestChecker_Failure(t *testing.T) {
+	checker := NewChecker(time.Second,
+		staticCheck("database", nil, errors.New("connection refused")),
+		staticCheck("migrations", MigrationStatus{}, nil))
+
+	report := checker.Run(context.Background())
+
+	assert.False(t, report.OK())
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+	assert.Equal(t, StatusOK, report.Checks[1].Status)
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	// Зависшая проверка, не слушающая ctx, не задерживает ответ дольше таймаута
+	hung := Check{Name: "database", Run: func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}}
+	checker := NewChecker(20*time.Millisecond, hung)
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.False(t, report.OK())
+	assert.Equal(t, "timed out", report.Checks[0].Error)
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUndefinedTable - код ошибки PostgreSQL для несуществующей таблицы
+const pgUndefinedTable = "42P01"
+
+// PoolStats - состояние пула соединений с базой
+type PoolStats struct {
+	MaxOpen        int     `json:"max_open"`
+	Open           int     `json:"open"`
+	InUse          int     `json:"in_use"`
+	Idle           int     `json:"idle"`
+	WaitCount      int64   `json:"wait_count"`
+	WaitDurationMS float64 `json:"wait_duration_ms"`
+}
+
+// Database проверяет доступность базы и сообщает состояние пула соединений
+func Database(db *sql.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) (interface{}, error) {
+			stats := db.Stats()
+			details := PoolStats{
+				MaxOpen:        stats.MaxOpenConnections,
+				Open:           stats.OpenConnections,
+				InUse:          stats.InUse,
+				Idle:           stats.Idle,
+				WaitCount:      stats.WaitCount,
+				WaitDurationMS: float64(stats.WaitDuration.Microseconds()) / 1000,
+			}
+			return details, db.PingContext(ctx)
+		},
+	}
+}
+
+// MigrationStatus - последняя версия схемы, записанная cmd/migrate;
+// Version равен nil, если ни одна миграция еще не применена
+type MigrationStatus struct {
+	Version *int64 `json:"version"`
+}
+
+// Migrations сообщает версию схемы из таблицы schema_migrations. Отсутствие
+// таблицы - ошибка: значит, cmd/migrate на этой базе не запускался
+func Migrations(db *sql.DB) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) (interface{}, error) {
+			var version int64
+			err := db.QueryRowContext(ctx,
+				`SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`).Scan(&version)
+			var pgErr *pgconn.PgError
+			switch {
+			case err == nil:
+				return MigrationStatus{Version: &version}, nil
+			case errors.Is(err, sql.ErrNoRows):
+				return MigrationStatus{}, nil
+			case errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable:
+				return nil, errors.New("schema_migrations table is missing: run cmd/migrate")
+			}
+			return nil, err
+		},
+	}
+}
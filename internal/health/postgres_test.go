@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const versionQuery = `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return db, mock
+}
+
+func TestMigrations_ReportsVersion(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(14))
+
+	details, err := Migrations(db).Run(context.Background())
+
+	require.NoError(t, err)
+	status := details.(MigrationStatus)
+	require.NotNil(t, status.Version)
+	assert.Equal(t, int64(14), *status.Version)
+}
+
+func TestMigrations_FailsWithoutTable(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(versionQuery).WillReturnError(&pgconn.PgError{
+		Code:    pgUndefinedTable,
+		Message: `relation "schema_migrations" does not exist`,
+	})
+
+	report := NewChecker(time.Second, Migrations(db)).Run(context.Background())
+
+	assert.False(t, report.OK())
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Error, "schema_migrations")
+}
+
+func TestMigrations_NoVersionsYet(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	details, err := Migrations(db).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Nil(t, details.(MigrationStatus).Version)
+}
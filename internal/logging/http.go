@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"qna-api/internal/recorder"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// RequestIDHeader - заголовок идентификатора запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - предельная длина принимаемого от клиента идентификатора
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext возвращает идентификатор запроса или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID кладет идентификатор запроса в контекст
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID берет идентификатор из заголовка X-Request-ID, а если его нет или он
+// недопустим, создает новый. Идентификатор возвращается в ответе и попадает в логи.
+func RequestID() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// validRequestID допускает только печатные ASCII-символы без пробелов, чтобы
+// идентификатор нельзя было использовать для подделки записей лога
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// accessEntry - дополнительные поля записи журнала запросов, которые заполняют внутренние слои
+type accessEntry struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+type accessEntryKey struct{}
+
+// Annotate добавляет поля к записи журнала запросов, например пользователя после аутентификации.
+// Вне AccessLog ничего не делает.
+func Annotate(ctx context.Context, attrs ...slog.Attr) {
+	entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry)
+	if !ok {
+		return
+	}
+	entry.mu.Lock()
+	entry.attrs = append(entry.attrs, attrs...)
+	entry.mu.Unlock()
+}
+
+// AccessLog пишет запись о каждом запросе: метод, шаблон маршрута, статус, длительность и
+// размер ответа. Запросы к маршрутам quiet (пробы и метрики) пишутся на уровне debug.
+func AccessLog(logger *slog.Logger, quiet ...string) mux.MiddlewareFunc {
+	quietRoutes := make(map[string]bool, len(quiet))
+	for _, route := range quiet {
+		quietRoutes[route] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			entry := &accessEntry{}
+			ctx := context.WithValue(r.Context(), accessEntryKey{}, entry)
+			start := time.Now()
+			rw := recorder.Wrap(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if quietRoutes[route] {
+				level = slog.LevelDebug
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rw.Status()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int64("bytes", rw.Bytes()),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			entry.mu.Lock()
+			attrs = append(attrs, entry.attrs...)
+			entry.mu.Unlock()
+			logger.LogAttrs(ctx, level, "Request", attrs...)
+		})
+	}
+}
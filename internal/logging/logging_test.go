@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// entries разбирает JSON-записи лога по одной на строку
+func entries(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestNew_InvalidLevel(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose")
+	assert.Error(t, err)
+}
+
+func TestNew_ContextAttributes(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := New(&logs, "info")
+	require.NoError(t, err)
+
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9},
+		SpanID:  trace.SpanID{0x00, 0xf0},
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"), span)
+	logger.With("component", "test").InfoContext(ctx, "Hello")
+	logger.DebugContext(ctx, "Hidden")
+
+	logged := entries(t, &logs)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "req-1", logged[0]["request_id"])
+	assert.Equal(t, span.TraceID().String(), logged[0]["trace_id"])
+	assert.Equal(t, span.SpanID().String(), logged[0]["span_id"])
+	assert.Equal(t, "test", logged[0]["component"])
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	router := mux.NewRouter()
+	router.HandleFunc("/questions", func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	})
+	router.Use(RequestID())
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"propagated", "gateway-7f3a", true},
+		{"missing", "", false},
+		{"with spaces", "forged\nentry", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/questions", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			id := rr.Header().Get(RequestIDHeader)
+			assert.Equal(t, id, seen)
+			if tt.keep {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+				assert.Len(t, id, 36)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := New(&logs, "info")
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/questions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		Annotate(r.Context(), slog.String("user", "user-1"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	})
+	router.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {})
+	router.Use(RequestID())
+	router.Use(AccessLog(logger, "/readyz"))
+
+	req := httptest.NewRequest("POST", "/questions/1", nil)
+	req.Header.Set(RequestIDHeader, "req-9")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	// Пробы пишутся на уровне debug и при уровне info не видны
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/readyz", nil))
+
+	logged := entries(t, &logs)
+	require.Len(t, logged, 1)
+	entry := logged[0]
+	assert.Equal(t, "Request", entry["msg"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/questions/{id}", entry["route"])
+	assert.Equal(t, "/questions/1", entry["path"])
+	assert.Equal(t, 201.0, entry["status"])
+	assert.Equal(t, 8.0, entry["bytes"])
+	assert.Equal(t, "user-1", entry["user"])
+	assert.Equal(t, "req-9", entry["request_id"])
+	assert.Contains(t, entry, "duration_ms")
+}
+
+func TestAnnotate_OutsideAccessLog(t *testing.T) {
+	// Без AccessLog аннотации просто отбрасываются
+	Annotate(context.Background(), slog.String("user", "user-1"))
+}
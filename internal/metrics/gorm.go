@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryStartKey - ключ времени начала запроса в экземпляре gorm.DB
+const queryStartKey = "metrics:query_start"
+
+// GORMPlugin измеряет длительность запросов GORM по операции и таблице
+type GORMPlugin struct {
+	metrics *Metrics
+}
+
+// GORMPlugin возвращает плагин для db.Use
+func (m *Metrics) GORMPlugin() *GORMPlugin {
+	return &GORMPlugin{metrics: m}
+}
+
+func (p *GORMPlugin) Name() string {
+	return "metrics"
+}
+
+// registrar - колбэк GORM, привязанный к позиции в цепочке операции
+type registrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// Initialize регистрирует колбэки до и после каждой операции GORM
+func (p *GORMPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	hooks := map[string][2]registrar{
+		"create": {callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		"query":  {callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		"update": {callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		"delete": {callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		"row":    {callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		"raw":    {callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for operation, hook := range hooks {
+		if err := hook[0].Register("metrics:before_"+operation, p.before); err != nil {
+			return err
+		}
+		if err := hook[1].Register("metrics:after_"+operation, p.after(operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GORMPlugin) before(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (p *GORMPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		p.metrics.queryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+	}
+}
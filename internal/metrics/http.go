@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"qna-api/internal/recorder"
+
+	"github.com/gorilla/mux"
+)
+
+// Middleware учитывает запросы по шаблону маршрута mux (/questions/{id}), а не по пути,
+// чтобы число рядов не росло с числом записей
+func (m *Metrics) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			start := time.Now()
+			rw := recorder.Wrap(w)
+			next.ServeHTTP(rw, r)
+
+			m.requests.WithLabelValues(r.Method, route, strconv.Itoa(rw.Status())).Inc()
+			m.requestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - префикс метрик сервиса
+const namespace = "qna"
+
+// Metrics - метрики сервиса в собственном реестре.
+// Методы учета безопасны для nil, поэтому слои работают и без метрик.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	inFlight        prometheus.Gauge
+	queryDuration   *prometheus.HistogramVec
+
+	questionsCreated prometheus.Counter
+	questionsDeleted prometheus.Counter
+	answersCreated   prometheus.Counter
+	answersDeleted   prometheus.Counter
+}
+
+// New создает метрики и регистрирует их вместе с метриками процесса и Go runtime
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served, including open event streams.",
+		}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		questionsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "questions_created_total",
+			Help:      "Questions created.",
+		}),
+		questionsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "questions_deleted_total",
+			Help:      "Questions deleted.",
+		}),
+		answersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "answers_created_total",
+			Help:      "Answers created.",
+		}),
+		answersDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "answers_deleted_total",
+			Help:      "Answers deleted.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		m.requests, m.requestDuration, m.inFlight, m.queryDuration,
+		m.questionsCreated, m.questionsDeleted, m.answersCreated, m.answersDeleted,
+	)
+	return m
+}
+
+// RegisterDBStats добавляет статистику пула соединений (sql.DB.Stats) с меткой db_name
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler отдает метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// QuestionCreated учитывает созданный вопрос
+func (m *Metrics) QuestionCreated() {
+	if m != nil {
+		m.questionsCreated.Inc()
+	}
+}
+
+// QuestionDeleted учитывает удаленный вопрос
+func (m *Metrics) QuestionDeleted() {
+	if m != nil {
+		m.questionsDeleted.Inc()
+	}
+}
+
+// AnswerCreated учитывает созданный ответ
+func (m *Metrics) AnswerCreated() {
+	if m != nil {
+		m.answersCreated.Inc()
+	}
+}
+
+// AnswerDeleted учитывает удаленный ответ
+func (m *Metrics) AnswerDeleted() {
+	if m != nil {
+		m.answersDeleted.Inc()
+	}
+}
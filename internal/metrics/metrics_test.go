@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"qna-api/internal/model"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMiddleware_RouteTemplate(t *testing.T) {
+	m := New()
+	router := mux.NewRouter()
+	router.HandleFunc("/questions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.inFlight))
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	router.Use(m.Middleware())
+
+	for _, path := range []string{"/questions/1", "/questions/2", "/questions/3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// Запросы к разным записям попадают в один ряд по шаблону маршрута
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/questions/{id}", "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.inFlight))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.requestDuration))
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `qna_http_requests_total{method="GET",route="/questions/{id}",status="404"} 3`)
+	assert.NotContains(t, rr.Body.String(), "/questions/1")
+}
+
+func TestBusinessCounters(t *testing.T) {
+	m := New()
+	m.QuestionCreated()
+	m.QuestionCreated()
+	m.AnswerCreated()
+	m.AnswerDeleted()
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.questionsCreated))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.questionsDeleted))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.answersCreated))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.answersDeleted))
+
+	// Без метрик учет ничего не делает
+	var disabled *Metrics
+	disabled.QuestionCreated()
+	disabled.AnswerDeleted()
+}
+
+func TestGORMPlugin(t *testing.T) {
+	m := New()
+	// DryRun строит запросы без соединения с базой, но проходит через колбэки
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(m.GORMPlugin()))
+
+	var question model.Question
+	db.First(&question, 1)
+	db.Create(&model.Answer{Text: "answer"})
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.queryDuration))
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `qna_db_query_duration_seconds_count{operation="query",table="questions"} 1`)
+	assert.Contains(t, rr.Body.String(), `qna_db_query_duration_seconds_count{operation="create",table="answers"} 1`)
+}
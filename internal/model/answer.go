@@ -2,17 +2,30 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Answer struct {
-	ID         int       `json:"id" gorm:"primaryKey"`
-	QuestionID int       `json:"question_id" gorm:"not null;index"`
-	UserID     string    `json:"user_id" gorm:"type:varchar(36);not null;index"`
-	Text       string    `json:"text" gorm:"type:text;not null"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ID         int            `json:"id" gorm:"primaryKey"`
+	QuestionID int            `json:"question_id" gorm:"not null;index"`
+	UserID     string         `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Text       string         `json:"text" gorm:"type:text;not null"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	Locked     bool           `json:"locked" gorm:"not null;default:false"`
+	Score      int            `json:"score" gorm:"not null;default:0"`
+
+	// Вычисляемое поле, заполняется при выборке
+	CommentCount int `json:"comment_count" gorm:"->;-:migration"`
 }
 
 type CreateAnswerRequest struct {
-	UserID string `json:"user_id" validate:"required,min=1"`
-	Text   string `json:"text" validate:"required,min=1"`
+	UserID string `json:"-"` // из токена, не из тела запроса
+	Text   string `json:"text" validate:"required,notblank,max=30000"`
+}
+
+type UpdateAnswerRequest struct {
+	Text *string `json:"text" validate:"omitnil,notblank,max=30000"`
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	CommentEntityQuestion = "question"
+	CommentEntityAnswer   = "answer"
+)
+
+// MaxCommentLength - максимальная длина комментария в символах
+const MaxCommentLength = 600
+
+// CommentSortOldest - комментарии всегда идут от старых к новым, используется в курсоре
+const CommentSortOldest = "oldest"
+
+// Comment - короткое уточнение к вопросу или ответу
+type Comment struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(16);not null;index:idx_comments_entity"`
+	EntityID   int       `json:"entity_id" gorm:"not null;index:idx_comments_entity"`
+	UserID     string    `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Text       string    `json:"text" gorm:"type:text;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateCommentRequest struct {
+	UserID string `json:"-"` // из токена, не из тела запроса
+	Text   string `json:"text" validate:"required,notblank,max=600"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// FieldError - ошибка в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// Типы доменных событий
+const (
+	EventQuestionCreated = "question.created"
+	EventQuestionUpdated = "question.updated"
+	EventQuestionDeleted = "question.deleted"
+	EventAnswerCreated   = "answer.created"
+	EventAnswerUpdated   = "answer.updated"
+	EventAnswerDeleted   = "answer.deleted"
+	EventAnswerAccepted  = "answer.accepted"
+	EventCommentCreated  = "comment.created"
+	EventCommentDeleted  = "comment.deleted"
+)
+
+// Event - доменное событие, которое записывается в журнал событий вместе с изменением данных.
+// ID - ID записи журнала событий: он растет, одинаков на всех экземплярах сервиса
+// и служит Last-Event-ID в потоках SSE и WebSocket.
+type Event struct {
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	QuestionID int         `json:"question_id"`
+	AnswerID   int         `json:"answer_id,omitempty"`
+	Tags       []string    `json:"tags,omitempty"` // теги вопроса, к которому относится событие
+	Data       interface{} `json:"data,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// AggregateQuestion - агрегат событий: вопрос вместе с его ответами.
+// Порядок событий гарантируется в пределах одного агрегата.
+const AggregateQuestion = "question"
+
+// OutboxEvent - запись журнала событий (outbox). Пишется в одной транзакции с изменением
+// данных; PublishedAt заполняется, когда событие доставлено во все приемники.
+type OutboxEvent struct {
+	ID            int64      `json:"id" gorm:"primaryKey"`
+	AggregateType string     `json:"aggregate_type" gorm:"type:varchar(32);not null;index:idx_events_aggregate"`
+	AggregateID   int        `json:"aggregate_id" gorm:"not null;index:idx_events_aggregate"`
+	Type          string     `json:"type" gorm:"type:varchar(32);not null"`
+	Payload       Event      `json:"payload" gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	PublishedAt   *time.Time `json:"published_at" gorm:"index:idx_events_unpublished,where:published_at IS NULL"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "events"
+}
+
+// AggregateKey возвращает ключ агрегата, например "question:12"
+func (e *OutboxEvent) AggregateKey() string {
+	return e.AggregateType + ":" + strconv.Itoa(e.AggregateID)
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type QuestionSort string
+
+const (
+	QuestionSortNewest       QuestionSort = "newest"
+	QuestionSortOldest       QuestionSort = "oldest"
+	QuestionSortMostAnswered QuestionSort = "most-answered"
+	QuestionSortScore        QuestionSort = "score"
+)
+
+// Valid проверяет, что сортировка поддерживается
+func (s QuestionSort) Valid() bool {
+	switch s {
+	case QuestionSortNewest, QuestionSortOldest, QuestionSortMostAnswered, QuestionSortScore:
+		return true
+	}
+	return false
+}
+
+type AnswerSort string
+
+const (
+	AnswerSortOldest AnswerSort = "oldest"
+	AnswerSortNewest AnswerSort = "newest"
+	AnswerSortScore  AnswerSort = "score"
+)
+
+// Valid проверяет, что сортировка поддерживается
+func (s AnswerSort) Valid() bool {
+	switch s {
+	case AnswerSortOldest, AnswerSortNewest, AnswerSortScore:
+		return true
+	}
+	return false
+}
+
+// Cursor - позиция последней записи страницы для keyset-пагинации.
+// Value хранит дополнительный ключ сортировки (число ответов или рейтинг),
+// Rank - релевантность для результатов поиска.
+type Cursor struct {
+	Sort      string    `json:"s"`
+	Value     int       `json:"v,omitempty"`
+	Rank      float64   `json:"r,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"i"`
+}
+
+// Encode возвращает непрозрачное строковое представление курсора
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор, полученный от клиента
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// QuestionListOptions - параметры выборки списка вопросов
+type QuestionListOptions struct {
+	Limit          int
+	Sort           QuestionSort
+	After          *Cursor
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	UserID         string // только вопросы этого автора
+	Unanswered     bool   // только вопросы без ответов
+	Unaccepted     bool   // только вопросы без принятого ответа
+	Tags           []string
+	TagMatch       TagMatch // all - вопрос содержит все теги, any - хотя бы один
+	TagIDs         []int    // теги после нормализации; заполняет сервис
+	IncludeDeleted bool
+}
+
+// QuestionPage - страница списка вопросов
+type QuestionPage struct {
+	Items      []Question `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// AnswerListOptions - параметры выборки ответов на вопрос
+type AnswerListOptions struct {
+	Limit          int
+	Sort           AnswerSort
+	After          *Cursor
+	IncludeDeleted bool
+}
+
+// AnswerPage - страница списка ответов
+type AnswerPage struct {
+	Items      []Answer `json:"items"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// CommentListOptions - параметры выборки комментариев к вопросу или ответу
+type CommentListOptions struct {
+	Limit int
+	After *Cursor
+}
+
+// CommentPage - страница списка комментариев
+type CommentPage struct {
+	Items      []Comment `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// GetQuestionOptions - параметры получения одного вопроса
+type GetQuestionOptions struct {
+	IncludeAnswers bool
+	AnswersLimit   int
+	AnswersSort    AnswerSort // порядок включенных ответов; пустой - AnswerSortOldest
+	IncludeDeleted bool
+}
+
+// GetAnswerOptions - параметры получения одного ответа
+type GetAnswerOptions struct {
+	IncludeDeleted bool
+}
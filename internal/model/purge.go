@@ -0,0 +1,10 @@
+package model
+
+// PurgeResult - число окончательно удаленных записей; в Answers входят
+// и ответы удаленных вопросов
+type PurgeResult struct {
+	Questions int64 `json:"questions"`
+	Answers   int64 `json:"answers"`
+	Comments  int64 `json:"comments"`
+	Votes     int64 `json:"votes"`
+}
@@ -1,16 +1,120 @@
 package model
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MaxTitleLength = 150
+	MaxBodyLength  = 30000
 )
 
 type Question struct {
-	ID        int       `json:"id" gorm:"primaryKey"`
-	Text      string    `json:"text" gorm:"type:text;not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	Answers   []Answer  `json:"answers,omitempty" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	ID        int            `json:"id" gorm:"primaryKey"`
+	UserID    string         `json:"user_id" gorm:"type:varchar(36);not null;default:'';index"`
+	Title     string         `json:"title" gorm:"type:varchar(150);not null;default:''"`
+	Slug      string         `json:"slug" gorm:"type:varchar(80);not null;default:''"`
+	Body      string         `json:"body" gorm:"column:text;type:text;not null"` // столбец text сохранен для совместимости
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	Locked    bool           `json:"locked" gorm:"not null;default:false"`
+	Score     int            `json:"score" gorm:"not null;default:0"`
+	Tags      []Tag          `json:"tags" gorm:"many2many:question_tags;constraint:OnDelete:CASCADE"`
+	Answers   []Answer       `json:"answers,omitempty" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+
+	// Принятый ответ; FK с ON DELETE SET NULL создается миграцией
+	AcceptedAnswerID *int `json:"accepted_answer_id" gorm:"index"`
+
+	// Вычисляемые поля, заполняются при выборке
+	AnswerCount  int `json:"answer_count" gorm:"->;-:migration"`
+	CommentCount int `json:"comment_count" gorm:"->;-:migration"`
 }
 
 type CreateQuestionRequest struct {
-	Text string `json:"text" validate:"required,min=1"`
+	UserID string   `json:"-"` // из токена, не из тела запроса
+	Title  string   `json:"title" validate:"required,notblank,max=150"`
+	Body   string   `json:"body" validate:"required,notblank,max=30000"`
+	Tags   []string `json:"tags" validate:"max=5"`
+}
+
+type UpdateQuestionRequest struct {
+	Title *string   `json:"title" validate:"omitnil,notblank,max=150"`
+	Body  *string   `json:"body" validate:"omitnil,notblank,max=30000"`
+	Tags  *[]string `json:"tags" validate:"omitnil,max=5"`
+}
+
+// UnmarshalJSON принимает устаревшее поле text (до появления заголовков) как body;
+// без заголовка им становится первая строка текста, как у перенесенных вопросов
+func (r *CreateQuestionRequest) UnmarshalJSON(data []byte) error {
+	type plain CreateQuestionRequest
+	req := struct {
+		plain
+		Text *string `json:"text"`
+	}{plain: plain(*r)}
+	if err := decodeStrict(data, &req); err != nil {
+		return err
+	}
+
+	*r = CreateQuestionRequest(req.plain)
+	if req.Text != nil && r.Body == "" {
+		r.Body = *req.Text
+		if r.Title == "" {
+			r.Title = TitleFromText(*req.Text)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON принимает устаревшее поле text как body
+func (r *UpdateQuestionRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateQuestionRequest
+	req := struct {
+		plain
+		Text *string `json:"text"`
+	}{plain: plain(*r)}
+	if err := decodeStrict(data, &req); err != nil {
+		return err
+	}
+
+	*r = UpdateQuestionRequest(req.plain)
+	if req.Text != nil && r.Body == nil {
+		r.Body = req.Text
+	}
+	return nil
+}
+
+// decodeStrict разбирает JSON, отклоняя неизвестные поля: настройки декодера
+// запроса не передаются в собственный UnmarshalJSON
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// TitleFromText возвращает первую строку текста не длиннее MaxTitleLength символов
+func TitleFromText(text string) string {
+	line, _, _ := strings.Cut(strings.Trim(text, " \t\r\n"), "\n")
+	title := []rune(strings.Trim(line, " \t\r"))
+	if len(title) > MaxTitleLength {
+		title = title[:MaxTitleLength]
+	}
+	return string(title)
+}
+
+// TagNames возвращает имена тегов вопроса
+func (q *Question) TagNames() []string {
+	if len(q.Tags) == 0 {
+		return nil
+	}
+	names := make([]string, len(q.Tags))
+	for i, tag := range q.Tags {
+		names[i] = tag.Name
+	}
+	return names
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	RevisionEntityQuestion = "question"
+	RevisionEntityAnswer   = "answer"
+)
+
+// FieldChange - старое и новое значение измененного поля
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Revision - запись журнала правок. Таблица только дополняется.
+type Revision struct {
+	ID         int                    `json:"id" gorm:"primaryKey"`
+	EntityType string                 `json:"entity_type" gorm:"type:varchar(16);not null;index:idx_revisions_entity"`
+	EntityID   int                    `json:"entity_id" gorm:"not null;index:idx_revisions_entity"`
+	EditorID   string                 `json:"editor_id" gorm:"type:varchar(36);not null"`
+	Changes    map[string]FieldChange `json:"changes" gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt  time.Time              `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	SearchTypeAll      = ""
+	SearchTypeQuestion = "question"
+	SearchTypeAnswer   = "answer"
+)
+
+// SearchSortRank - сортировка результатов поиска по релевантности, используется в курсоре
+const SearchSortRank = "rank"
+
+// SearchOptions - параметры полнотекстового поиска
+type SearchOptions struct {
+	Query string
+	Type  string // question, answer или пусто для обоих
+	Limit int
+	After *Cursor
+}
+
+// SearchResult - найденный вопрос или ответ с фрагментом текста
+type SearchResult struct {
+	Type       string    `json:"type"`
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	Title      string    `json:"title"` // заголовок вопроса, к которому относится результат
+	Rank       float64   `json:"rank"`
+	Snippet    string    `json:"snippet"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SearchPage - страница результатов поиска, отсортированных по релевантности
+type SearchPage struct {
+	Items      []SearchResult `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxSlugLength - максимальная длина slug в символах
+const MaxSlugLength = 80
+
+// Slugify строит slug для URL из заголовка: буквы и цифры в нижнем регистре,
+// остальные символы заменяются одним дефисом
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := []rune(b.String())
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+	}
+	return strings.TrimRight(string(slug), "-")
+}
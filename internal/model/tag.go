@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	MaxTagLength       = 32
+	MaxTagsPerQuestion = 5
+)
+
+type Tag struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Вычисляемое поле, заполняется только при выборке списка тегов
+	QuestionCount int `json:"question_count,omitempty" gorm:"->;-:migration"`
+}
+
+// TagSynonym - альтернативное имя тега; при создании и поиске заменяется основным
+type TagSynonym struct {
+	Name  string `json:"name" gorm:"primaryKey;type:varchar(32)"`
+	TagID int    `json:"tag_id" gorm:"not null;index"`
+}
+
+type TagMatch string
+
+const (
+	TagMatchAll TagMatch = "all"
+	TagMatchAny TagMatch = "any"
+)
+
+// TagListOptions - параметры выборки списка тегов
+type TagListOptions struct {
+	Prefix string
+	Limit  int
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,notblank,max=32"`
+}
+
+type MergeTagRequest struct {
+	Into string `json:"into" validate:"required,notblank,max=32"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// UserRole - роль, назначенная пользователю администратором
+type UserRole struct {
+	UserID    string    `json:"user_id" gorm:"primaryKey;type:varchar(36)"`
+	Role      string    `json:"role" gorm:"type:varchar(16);not null"`
+	GrantedBy string    `json:"granted_by" gorm:"type:varchar(36);not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
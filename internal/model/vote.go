@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	VoteEntityQuestion = "question"
+	VoteEntityAnswer   = "answer"
+)
+
+// Vote - голос пользователя за вопрос или ответ.
+// Уникальный индекс гарантирует один голос пользователя за запись.
+type Vote struct {
+	ID         int       `json:"-" gorm:"primaryKey"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(16);not null;uniqueIndex:idx_votes_entity_user"`
+	EntityID   int       `json:"entity_id" gorm:"not null;uniqueIndex:idx_votes_entity_user"`
+	UserID     string    `json:"user_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_votes_entity_user"`
+	Value      int       `json:"value" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// VoteRequest - голос +1/-1; 0 отменяет ранее отданный голос
+type VoteRequest struct {
+	Value *int `json:"value" validate:"required,oneof=-1 0 1"`
+}
+
+// VoteResult - рейтинг записи после применения голоса
+type VoteResult struct {
+	Score int `json:"score"`
+	Value int `json:"value"`
+}
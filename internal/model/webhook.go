@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+)
+
+// События, на которые можно подписать вебхук
+var WebhookEvents = []string{
+	EventQuestionCreated,
+	EventQuestionUpdated,
+	EventQuestionDeleted,
+	EventAnswerCreated,
+	EventAnswerUpdated,
+	EventAnswerDeleted,
+	EventAnswerAccepted,
+	EventCommentCreated,
+	EventCommentDeleted,
+}
+
+// MinWebhookSecretLength - минимальная длина секрета для подписи доставок
+const MinWebhookSecretLength = 16
+
+// DeliverySortNewest - доставки идут от новых к старым, используется в курсоре
+const DeliverySortNewest = "newest"
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook - адрес, на который отправляются события. Пустой Events означает все события.
+type Webhook struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url" gorm:"type:varchar(2048);not null"`
+	Events    []string  `json:"events" gorm:"type:jsonb;serializer:json;not null"`
+	Secret    string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(36);not null;default:''"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Accepts проверяет, подписан ли вебхук на событие
+func (w *Webhook) Accepts(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery - запись outbox: событие, которое нужно доставить вебхуку.
+// Создается в одной транзакции с изменением данных.
+type WebhookDelivery struct {
+	ID             int        `json:"id" gorm:"primaryKey"`
+	WebhookID      int        `json:"webhook_id" gorm:"not null;index"`
+	EventType      string     `json:"event_type" gorm:"type:varchar(32);not null"`
+	Payload        Event      `json:"payload" gorm:"type:jsonb;serializer:json;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(16);not null;default:'pending'"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null;index"`
+	ResponseStatus int        `json:"response_status" gorm:"not null;default:0"`
+	LastError      string     `json:"last_error" gorm:"type:text;not null;default:''"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+
+	Webhook *Webhook `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret" validate:"required,min=16,max=255"`
+}
+
+// DeliveryListOptions - параметры выборки доставок вебхука
+type DeliveryListOptions struct {
+	Limit  int
+	After  *Cursor
+	Status string
+}
+
+// DeliveryPage - страница доставок вебхука, от новых к старым
+type DeliveryPage struct {
+	Items      []WebhookDelivery `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"qna-api/internal/model"
+)
+
+// Store - журнал событий, который разбирает диспетчер
+type Store interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error)
+}
+
+// Sink - приемник событий. Publish возвращает nil, только когда приемник принял событие:
+// иначе событие будет отправлено повторно во все приемники.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event model.OutboxEvent) error
+}
+
+// Dispatcher публикует события из журнала во все приемники.
+// Доставка - не менее одного раза: ID события (payload.id) позволяет отбросить повторы.
+// События одного агрегата публикуются строго по порядку: после ошибки остальные события
+// агрегата ждут следующего прохода.
+type Dispatcher struct {
+	store Store
+	sinks []Sink
+
+	BatchSize int
+}
+
+// NewDispatcher создает диспетчер с настройками по умолчанию
+func NewDispatcher(store Store, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		store:     store,
+		sinks:     sinks,
+		BatchSize: 100,
+	}
+}
+
+// Run раз в interval публикует накопившиеся события, пока не будет отменен ctx
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchOnce(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to dispatch events", "error", err)
+			}
+		}
+	}
+}
+
+// DispatchOnce публикует одну пачку событий и возвращает число опубликованных
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	return d.store.ProcessOutbox(ctx, d.BatchSize, func(events []model.OutboxEvent) []int64 {
+		return d.publish(ctx, events)
+	})
+}
+
+// publish отправляет события по порядку и возвращает ID принятых всеми приемниками
+func (d *Dispatcher) publish(ctx context.Context, events []model.OutboxEvent) []int64 {
+	var published []int64
+	blocked := map[string]bool{}
+
+	for _, event := range events {
+		key := event.AggregateKey()
+		if blocked[key] {
+			continue
+		}
+		if err := d.deliver(ctx, event); err != nil {
+			slog.WarnContext(ctx, "Failed to publish event", "event_id", event.ID, "aggregate", key, "error", err)
+			blocked[key] = true
+			continue
+		}
+		published = append(published, event.ID)
+	}
+	return published
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event model.OutboxEvent) error {
+	// Получатели видят ID записи журнала: он не меняется между повторами
+	event.Payload.ID = uint64(event.ID)
+	for _, sink := range d.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"qna-api/internal/model"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaProducer - синхронная отправка сообщения в Kafka; возвращает nil после подтверждения
+// брокером. Ей соответствует KafkaWriter, а в тестах - подставной продюсер.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaSink публикует события в topic с ключом агрегата: события одного вопроса
+// попадают в одну партицию и читаются в порядке записи
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	value, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, s.topic, []byte(event.AggregateKey()), value)
+}
+
+// KafkaWriter - KafkaProducer на основе kafka-go: партиция выбирается по хешу ключа,
+// запись подтверждается всеми синхронными репликами
+type KafkaWriter struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaWriter создает продюсер для брокеров brokers ("host:port")
+func NewKafkaWriter(brokers ...string) *KafkaWriter {
+	return &KafkaWriter{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}
+}
+
+func (w *KafkaWriter) Produce(ctx context.Context, topic string, key, value []byte) error {
+	return w.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value})
+}
+
+func (w *KafkaWriter) Close() error {
+	return w.writer.Close()
+}
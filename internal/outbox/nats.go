@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"qna-api/internal/model"
+)
+
+// NATSConn - часть клиента NATS, которая нужна приемнику; ей соответствует *nats.Conn
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+	Flush() error
+}
+
+// NATSSink публикует события в subject "<prefix>.<тип события>", например
+// "qna.events.answer.created"
+type NATSSink struct {
+	conn   NATSConn
+	prefix string
+}
+
+func NewNATSSink(conn NATSConn, prefix string) *NATSSink {
+	return &NATSSink{conn: conn, prefix: prefix}
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	if err := s.conn.Publish(s.prefix+"."+event.Type, data); err != nil {
+		return err
+	}
+	// Flush дожидается ответа сервера, иначе публикация не подтверждена
+	return s.conn.Flush()
+}
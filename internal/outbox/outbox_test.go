@@ -0,0 +1,353 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"qna-api/internal/events"
+	"qna-api/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore - журнал событий в памяти
+type memoryStore struct {
+	events    []model.OutboxEvent
+	published map[int64]bool
+}
+
+func newMemoryStore(events ...model.OutboxEvent) *memoryStore {
+	return &memoryStore{events: events, published: map[int64]bool{}}
+}
+
+func (s *memoryStore) ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error) {
+	var pending []model.OutboxEvent
+	for _, event := range s.events {
+		if !s.published[event.ID] && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	ids := publish(pending)
+	for _, id := range ids {
+		s.published[id] = true
+	}
+	return len(ids), nil
+}
+
+func (s *memoryStore) LatestEventID(ctx context.Context) (int64, error) {
+	var latest int64
+	for _, event := range s.events {
+		latest = max(latest, event.ID)
+	}
+	return latest, nil
+}
+
+func (s *memoryStore) EventsAfter(ctx context.Context, afterID int64, limit int) ([]model.OutboxEvent, error) {
+	var result []model.OutboxEvent
+	for _, event := range s.events {
+		if event.ID > afterID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// recordingSink запоминает принятые события и отклоняет события из failing
+type recordingSink struct {
+	received []int64
+	failing  map[int64]bool
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	if s.failing[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.received = append(s.received, event.ID)
+	return nil
+}
+
+// fakeNATS - подставной NATS-клиент
+type fakeNATS struct {
+	subjects []string
+	messages [][]byte
+	flushes  int
+}
+
+func (c *fakeNATS) Publish(subject string, data []byte) error {
+	c.subjects = append(c.subjects, subject)
+	c.messages = append(c.messages, data)
+	return nil
+}
+
+func (c *fakeNATS) Flush() error {
+	c.flushes++
+	return nil
+}
+
+// fakeKafka - подставной Kafka-продюсер: сообщения раскладываются по ключам как по партициям
+type fakeKafka struct {
+	topic      string
+	partitions map[string][]model.Event
+}
+
+func (p *fakeKafka) Produce(ctx context.Context, topic string, key, value []byte) error {
+	var event model.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return err
+	}
+	p.topic = topic
+	p.partitions[string(key)] = append(p.partitions[string(key)], event)
+	return nil
+}
+
+func outboxEvent(id int64, questionID int, eventType string) model.OutboxEvent {
+	return model.OutboxEvent{
+		ID:            id,
+		AggregateType: model.AggregateQuestion,
+		AggregateID:   questionID,
+		Type:          eventType,
+		Payload:       model.Event{Type: eventType, QuestionID: questionID},
+	}
+}
+
+func TestDispatcher_PublishesInOrder(t *testing.T) {
+	store := newMemoryStore(
+		outboxEvent(1, 1, model.EventQuestionCreated),
+		outboxEvent(2, 2, model.EventQuestionCreated),
+		outboxEvent(3, 1, model.EventAnswerCreated),
+	)
+	sink := &recordingSink{}
+	d := NewDispatcher(store, sink)
+
+	n, err := d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 2, 3}, sink.received)
+
+	// Опубликованные события не отправляются повторно
+	n, _ = d.DispatchOnce(context.Background())
+	assert.Zero(t, n)
+}
+
+func TestDispatcher_BlocksAggregateAfterFailure(t *testing.T) {
+	store := newMemoryStore(
+		outboxEvent(1, 1, model.EventQuestionCreated),
+		outboxEvent(2, 2, model.EventQuestionCreated),
+		outboxEvent(3, 1, model.EventAnswerCreated),
+	)
+	sink := &recordingSink{failing: map[int64]bool{1: true}}
+	d := NewDispatcher(store, sink)
+
+	// Событие 3 ждет события 1 того же вопроса, другой вопрос не задерживается
+	n, err := d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{2}, sink.received)
+
+	sink.failing = nil
+	n, _ = d.DispatchOnce(context.Background())
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{2, 1, 3}, sink.received)
+}
+
+func TestDispatcher_RetriesAllSinks(t *testing.T) {
+	store := newMemoryStore(outboxEvent(1, 1, model.EventQuestionCreated))
+	first := &recordingSink{}
+	second := &recordingSink{failing: map[int64]bool{1: true}}
+	d := NewDispatcher(store, first, second)
+
+	d.DispatchOnce(context.Background())
+	second.failing = nil
+	d.DispatchOnce(context.Background())
+
+	// Доставка не менее одного раза: первый приемник получил событие дважды
+	assert.Equal(t, []int64{1, 1}, first.received)
+	assert.Equal(t, []int64{1}, second.received)
+	assert.True(t, store.published[1])
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	d := NewDispatcher(newMemoryStore(
+		outboxEvent(1, 1, model.EventQuestionCreated),
+		outboxEvent(2, 1, model.EventAnswerCreated),
+	), sink)
+	d.DispatchOnce(context.Background())
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var logged []model.OutboxEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.OutboxEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		logged = append(logged, event)
+	}
+	require.Len(t, logged, 2)
+	assert.Equal(t, int64(1), logged[0].ID)
+	assert.Equal(t, uint64(1), logged[0].Payload.ID)
+	assert.Equal(t, model.EventAnswerCreated, logged[1].Type)
+}
+
+func TestNATSSink(t *testing.T) {
+	conn := &fakeNATS{}
+	d := NewDispatcher(newMemoryStore(outboxEvent(7, 3, model.EventAnswerCreated)), NewNATSSink(conn, "qna.events"))
+	d.DispatchOnce(context.Background())
+
+	require.Len(t, conn.messages, 1)
+	assert.Equal(t, "qna.events.answer.created", conn.subjects[0])
+	assert.Equal(t, 1, conn.flushes)
+
+	var event model.Event
+	require.NoError(t, json.Unmarshal(conn.messages[0], &event))
+	assert.Equal(t, uint64(7), event.ID)
+	assert.Equal(t, 3, event.QuestionID)
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &fakeKafka{partitions: map[string][]model.Event{}}
+	d := NewDispatcher(newMemoryStore(
+		outboxEvent(1, 1, model.EventQuestionCreated),
+		outboxEvent(2, 2, model.EventQuestionCreated),
+		outboxEvent(3, 1, model.EventAnswerCreated),
+	), NewKafkaSink(producer, "qna-events"))
+	d.DispatchOnce(context.Background())
+
+	assert.Equal(t, "qna-events", producer.topic)
+	require.Len(t, producer.partitions["question:1"], 2)
+	assert.Equal(t, model.EventQuestionCreated, producer.partitions["question:1"][0].Type)
+	assert.Equal(t, model.EventAnswerCreated, producer.partitions["question:1"][1].Type)
+	assert.Len(t, producer.partitions["question:2"], 1)
+}
+
+// publishedIDs возвращает ID событий, полученных подписчиком к этому моменту
+func publishedIDs(sub *events.Subscription) []uint64 {
+	var ids []uint64
+	for len(sub.Events()) > 0 {
+		ids = append(ids, (<-sub.Events()).ID)
+	}
+	return ids
+}
+
+func TestTail_FeedsEveryInstance(t *testing.T) {
+	store := newMemoryStore(
+		outboxEvent(1, 1, model.EventQuestionCreated),
+		outboxEvent(2, 1, model.EventAnswerCreated),
+	)
+
+	// Каждый экземпляр читает журнал сам и получает все события с ID записей журнала
+	var subs []*events.Subscription
+	var tails []*Tail
+	for i := 0; i < 2; i++ {
+		broker := events.NewBroker(10)
+		sub, _ := broker.Subscribe(nil, 0)
+		defer sub.Close()
+		subs = append(subs, sub)
+		tail := NewTail(store, broker)
+		require.NoError(t, tail.Start(context.Background(), 10))
+		tails = append(tails, tail)
+	}
+	for i, tail := range tails {
+		n, err := tail.PollOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []uint64{1, 2}, publishedIDs(subs[i]))
+	}
+
+	// Журнал не помечается опубликованным: внешние приемники получат события от диспетчера
+	assert.Empty(t, store.published)
+
+	store.events = append(store.events, outboxEvent(3, 2, model.EventQuestionCreated))
+	for i, tail := range tails {
+		tail.PollOnce(context.Background())
+		assert.Equal(t, []uint64{3}, publishedIDs(subs[i]))
+	}
+}
+
+func TestTail_StartReplaysBacklog(t *testing.T) {
+	store := newMemoryStore()
+	for id := int64(1); id <= 5; id++ {
+		store.events = append(store.events, outboxEvent(id, 1, model.EventQuestionUpdated))
+	}
+	broker := events.NewBroker(10)
+	tail := NewTail(store, broker)
+	require.NoError(t, tail.Start(context.Background(), 2))
+	tail.PollOnce(context.Background())
+
+	// Последние события попадают в буфер брокера для возобновления по Last-Event-ID
+	sub, missed := broker.Subscribe(nil, 3)
+	defer sub.Close()
+	require.Len(t, missed, 2)
+	assert.Equal(t, uint64(4), missed[0].ID)
+	assert.Equal(t, uint64(5), missed[1].ID)
+}
+
+func TestTail_WaitsForUncommittedEvent(t *testing.T) {
+	now := time.Now()
+	event := func(id int64) model.OutboxEvent {
+		e := outboxEvent(id, 1, model.EventAnswerCreated)
+		e.CreatedAt = now
+		return e
+	}
+	store := newMemoryStore(event(1), event(3))
+	broker := events.NewBroker(10)
+	sub, _ := broker.Subscribe(nil, 0)
+	defer sub.Close()
+	tail := NewTail(store, broker)
+	tail.now = func() time.Time { return now }
+
+	// Транзакция события 2 еще не зафиксирована: событие 3 ждет его, чтобы ID шли по порядку
+	n, err := tail.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []uint64{1}, publishedIDs(sub))
+
+	store.events = []model.OutboxEvent{event(1), event(2), event(3), event(5)}
+	tail.PollOnce(context.Background())
+	assert.Equal(t, []uint64{2, 3}, publishedIDs(sub))
+
+	// Событие 4 так и не появилось: после GapTimeout его транзакция считается откаченной
+	tail.PollOnce(context.Background())
+	assert.Empty(t, publishedIDs(sub))
+	tail.now = func() time.Time { return now.Add(tail.GapTimeout) }
+	tail.PollOnce(context.Background())
+	assert.Equal(t, []uint64{5}, publishedIDs(sub))
+	assert.Empty(t, tail.gaps)
+}
+
+func TestTail_OldGapsDoNotBlock(t *testing.T) {
+	// Пропуски в старой части журнала (откаченные транзакции) не задерживают публикацию при запуске
+	old := outboxEvent(3, 1, model.EventAnswerCreated)
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	store := newMemoryStore(outboxEvent(1, 1, model.EventQuestionCreated), old)
+	broker := events.NewBroker(10)
+	sub, _ := broker.Subscribe(nil, 0)
+	defer sub.Close()
+
+	tail := NewTail(store, broker)
+	n, err := tail.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []uint64{1, 3}, publishedIDs(sub))
+}
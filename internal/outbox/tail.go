@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"qna-api/internal/events"
+	"qna-api/internal/model"
+)
+
+// TailStore - чтение журнала событий по порядку ID
+type TailStore interface {
+	LatestEventID(ctx context.Context) (int64, error)
+	EventsAfter(ctx context.Context, afterID int64, limit int) ([]model.OutboxEvent, error)
+}
+
+// Tail передает события журнала во внутрипроцессный получатель, например брокер SSE и WebSocket.
+// В отличие от Dispatcher журнал читает каждый экземпляр сервиса, поэтому события получают
+// клиенты, подключенные к любому экземпляру.
+//
+// ID событий выдаются до фиксации транзакций, поэтому событие с меньшим ID может стать видимым
+// позже. Tail публикует события строго по возрастанию ID: после пропуска в номерах он ждет
+// недостающее событие не дольше GapTimeout, а затем считает его транзакцию откаченной.
+type Tail struct {
+	store     TailStore
+	publisher events.Publisher
+	cursor    int64               // ID последнего события, после которого все опубликованы или пропущены
+	gaps      map[int64]time.Time // пропуски в номерах и когда они замечены
+	now       func() time.Time
+
+	BatchSize  int
+	GapTimeout time.Duration
+}
+
+// NewTail создает чтение журнала с настройками по умолчанию
+func NewTail(store TailStore, publisher events.Publisher) *Tail {
+	return &Tail{
+		store:      store,
+		publisher:  publisher,
+		gaps:       map[int64]time.Time{},
+		now:        time.Now,
+		BatchSize:  100,
+		GapTimeout: 5 * time.Second,
+	}
+}
+
+// Start начинает чтение с последних backlog событий журнала, чтобы клиенты могли возобновить
+// поток по Last-Event-ID и после перезапуска экземпляра
+func (t *Tail) Start(ctx context.Context, backlog int) error {
+	latest, err := t.store.LatestEventID(ctx)
+	if err != nil {
+		return err
+	}
+	t.cursor = max(latest-int64(backlog), 0)
+	return nil
+}
+
+// Run раз в interval публикует новые события журнала, пока не будет отменен ctx
+func (t *Tail) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := t.PollOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to read events", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollOnce публикует новые события журнала и возвращает число опубликованных
+func (t *Tail) PollOnce(ctx context.Context) (int, error) {
+	batch, err := t.store.EventsAfter(ctx, t.cursor, t.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	now := t.now()
+	published := 0
+	blocked := false
+	expected := t.cursor + 1
+	for _, event := range batch {
+		// Пропущенные номера перед событием: после первого неистекшего пропуска публикация
+		// останавливается, но отсчет ожидания начинается для всех пропусков пачки
+		for id := expected; id < event.ID; id++ {
+			if !t.gapExpired(id, event, now) {
+				blocked = true
+				continue
+			}
+			if !blocked {
+				delete(t.gaps, id)
+				t.cursor = id
+			}
+		}
+		expected = event.ID + 1
+		if blocked {
+			continue
+		}
+
+		payload := event.Payload
+		payload.ID = uint64(event.ID)
+		t.publisher.Publish(payload)
+		delete(t.gaps, event.ID)
+		t.cursor = event.ID
+		published++
+	}
+	return published, nil
+}
+
+// gapExpired сообщает, можно ли больше не ждать события id. Ожидание истекает через GapTimeout
+// после того, как пропуск замечен, или если следующее за пропуском событие записано раньше
+// (так при запуске не приходится ждать пропусков в старой части журнала).
+func (t *Tail) gapExpired(id int64, next model.OutboxEvent, now time.Time) bool {
+	if !next.CreatedAt.IsZero() && now.Sub(next.CreatedAt) >= t.GapTimeout {
+		return true
+	}
+	noticed, ok := t.gaps[id]
+	if !ok {
+		t.gaps[id] = now
+		noticed = now
+	}
+	return now.Sub(noticed) >= t.GapTimeout
+}
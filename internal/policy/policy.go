@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"errors"
+
+	"qna-api/internal/auth"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("authentication required")
+	ErrInsufficientRole = errors.New("insufficient role")
+)
+
+// Rule - требование маршрута к вызывающему
+type Rule struct {
+	Authenticated bool
+	MinRole       auth.Role
+}
+
+var (
+	Public        = Rule{}
+	Authenticated = Rule{Authenticated: true, MinRole: auth.RoleUser}
+	Moderator     = Rule{Authenticated: true, MinRole: auth.RoleModerator}
+	Admin         = Rule{Authenticated: true, MinRole: auth.RoleAdmin}
+)
+
+// Check проверяет, что вызывающий удовлетворяет правилу
+func (r Rule) Check(identity auth.Identity, authenticated bool) error {
+	if !r.Authenticated {
+		return nil
+	}
+	if !authenticated {
+		return ErrUnauthenticated
+	}
+	if !identity.Role.AtLeast(r.MinRole) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+// CanModerate сообщает, может ли пользователь управлять чужим контентом
+func CanModerate(actor auth.Identity) bool {
+	return actor.Role.AtLeast(auth.RoleModerator)
+}
+
+// CanModify разрешает изменение контента автору и модераторам.
+// Контент без автора могут изменять только модераторы.
+func CanModify(actor auth.Identity, ownerID string) bool {
+	if CanModerate(actor) {
+		return true
+	}
+	return ownerID != "" && actor.Subject == ownerID
+}
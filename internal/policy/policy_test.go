@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"testing"
+
+	"qna-api/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Check(t *testing.T) {
+	user := auth.Identity{Subject: "user-1", Role: auth.RoleUser}
+	moderator := auth.Identity{Subject: "moderator-1", Role: auth.RoleModerator}
+
+	assert.NoError(t, Public.Check(auth.Identity{}, false))
+	assert.ErrorIs(t, Authenticated.Check(auth.Identity{}, false), ErrUnauthenticated)
+	assert.NoError(t, Authenticated.Check(user, true))
+	assert.ErrorIs(t, Moderator.Check(user, true), ErrInsufficientRole)
+	assert.NoError(t, Moderator.Check(moderator, true))
+	assert.ErrorIs(t, Admin.Check(moderator, true), ErrInsufficientRole)
+}
+
+func TestCanModify(t *testing.T) {
+	user := auth.Identity{Subject: "user-1", Role: auth.RoleUser}
+	admin := auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin}
+
+	assert.True(t, CanModify(user, "user-1"))
+	assert.False(t, CanModify(user, "user-2"))
+	assert.False(t, CanModify(user, ""))
+	assert.True(t, CanModify(admin, "user-2"))
+	assert.True(t, CanModify(admin, ""))
+}
@@ -0,0 +1,40 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"qna-api/internal/model"
+)
+
+// ContentType - тип содержимого ответа об ошибке по RFC 7807
+const ContentType = "application/problem+json"
+
+// Problem - описание ошибки по RFC 7807. Code - машиночитаемый код ошибки,
+// Errors - ошибки отдельных полей запроса.
+type Problem struct {
+	Type   string             `json:"type"`
+	Title  string             `json:"title"`
+	Status int                `json:"status"`
+	Detail string             `json:"detail,omitempty"`
+	Code   string             `json:"code"`
+	Errors []model.FieldError `json:"errors,omitempty"`
+}
+
+// New создает описание ошибки; title берется из текста HTTP-статуса
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write отправляет описание ошибки клиенту
+func Write(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
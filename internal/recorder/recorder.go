@@ -0,0 +1,63 @@
+package recorder
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter запоминает код и размер ответа для middleware метрик, трассировки и журнала запросов.
+// Потоки SSE и WebSocket продолжают работать через Flush и Hijack.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// Wrap оборачивает w; код ответа по умолчанию 200
+func Wrap(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status возвращает первый записанный код ответа
+func (r *ResponseWriter) Status() int {
+	return r.status
+}
+
+// Bytes возвращает число записанных байт тела ответа
+func (r *ResponseWriter) Bytes() int64 {
+	return r.bytes
+}
+
+func (r *ResponseWriter) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *ResponseWriter) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *ResponseWriter) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	// После Hijack ответ пишет WebSocket, соединение считается 101
+	r.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (r *ResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,32 @@
+package recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseWriter_KeepsFlusher(t *testing.T) {
+	recorder := Wrap(httptest.NewRecorder())
+
+	// Потоки SSE проверяют http.Flusher у ResponseWriter
+	var w http.ResponseWriter = recorder
+	_, ok := w.(http.Flusher)
+	assert.True(t, ok)
+
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, http.StatusCreated, recorder.Status())
+}
+
+func TestResponseWriter_DefaultStatusAndBytes(t *testing.T) {
+	recorder := Wrap(httptest.NewRecorder())
+	_, err := recorder.Write([]byte("ok"))
+	assert.NoError(t, err)
+	_, err = recorder.Write([]byte("!"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Status())
+	assert.Equal(t, int64(3), recorder.Bytes())
+}
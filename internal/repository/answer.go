@@ -1,37 +1,174 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Столбцы ответа вместе с числом комментариев
+const answerColumns = "answers.*, " + answerCommentCountExpr + " AS comment_count"
+
 // Методы для ответов
-func (r *Repository) CreateAnswer(answer *model.Answer) error {
-	// Проверяем существование вопроса
-	var question model.Question
-	if err := r.db.First(&question, answer.QuestionID).Error; err != nil {
-		return err
-	}
 
-	result := r.db.Create(answer)
-	return result.Error
+// CreateAnswer сохраняет ответ и событие о нем в одной транзакции
+func (r *Repository) CreateAnswer(ctx context.Context, answer *model.Answer) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		// Проверяем существование вопроса
+		var question model.Question
+		if err := tx.First(&question, answer.QuestionID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(answer).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventAnswerCreated,
+			QuestionID: answer.QuestionID,
+			AnswerID:   answer.ID,
+			Data:       answer,
+			CreatedAt:  answer.CreatedAt,
+		})
+	})
 }
 
-func (r *Repository) GetAnswerByID(id int) (*model.Answer, error) {
+func (r *Repository) GetAnswerByID(ctx context.Context, id int) (*model.Answer, error) {
 	var answer model.Answer
-	result := r.db.First(&answer, id)
+	result := r.db.WithContext(ctx).Select(answerColumns).First(&answer, id)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, dbError(result.Error)
 	}
 	return &answer, nil
 }
 
-func (r *Repository) GetAnswersByQuestionID(questionID int) ([]model.Answer, error) {
-	var answers []model.Answer
-	result := r.db.Where("question_id = ?", questionID).Find(&answers)
-	return answers, result.Error
+func (r *Repository) ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	return listAnswers(r.db.WithContext(ctx).Where("question_id = ?", questionID), opts)
+}
+
+// ListAnswersByUserID возвращает страницу ответов автора по всем вопросам
+func (r *Repository) ListAnswersByUserID(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	return listAnswers(r.db.WithContext(ctx).Where("user_id = ?", userID), opts)
 }
 
-func (r *Repository) DeleteAnswer(id int) error {
-	result := r.db.Delete(&model.Answer{}, id)
-	return result.Error
+// listAnswers применяет сортировку и курсор к отфильтрованной выборке ответов
+func listAnswers(query *gorm.DB, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	c := opts.After
+	switch opts.Sort {
+	case model.AnswerSortNewest:
+		if c != nil {
+			query = query.Where("(created_at, id) < (?, ?)", c.CreatedAt, c.ID)
+		}
+		query = query.Order("created_at DESC, id DESC")
+	case model.AnswerSortScore:
+		if c != nil {
+			query = query.Where("(score, created_at, id) < (?, ?, ?)", c.Value, c.CreatedAt, c.ID)
+		}
+		query = query.Order("score DESC, created_at DESC, id DESC")
+	default:
+		if c != nil {
+			query = query.Where("(created_at, id) > (?, ?)", c.CreatedAt, c.ID)
+		}
+		query = query.Order("created_at ASC, id ASC")
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	answers := []model.Answer{}
+	if err := query.Select(answerColumns).Limit(opts.Limit + 1).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.AnswerPage{Items: answers}
+	if len(answers) > opts.Limit {
+		page.Items = answers[:opts.Limit]
+		last := page.Items[opts.Limit-1]
+		page.NextCursor = model.Cursor{
+			Sort:      string(opts.Sort),
+			Value:     last.Score,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
+
+// UpdateAnswer сохраняет изменения ответа, запись о правке и событие в одной транзакции
+func (r *Repository) UpdateAnswer(ctx context.Context, answer *model.Answer, revision *model.Revision) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(answer).Select("Text", "UpdatedAt").Updates(answer).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventAnswerUpdated,
+			QuestionID: answer.QuestionID,
+			AnswerID:   answer.ID,
+			Data:       answer,
+			CreatedAt:  answer.UpdatedAt,
+		})
+	})
+}
+
+func (r *Repository) SetAnswerLocked(ctx context.Context, id int, locked bool) error {
+	result := r.db.WithContext(ctx).Model(&model.Answer{}).Where("id = ?", id).Update("locked", locked)
+	if result.Error != nil {
+		return dbError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAnswer мягко удаляет ответ; удаленный ответ перестает быть принятым
+func (r *Repository) DeleteAnswer(ctx context.Context, id int) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		var answer model.Answer
+		if err := tx.First(&answer, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Question{}).Where("accepted_answer_id = ?", id).
+			UpdateColumn("accepted_answer_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&answer).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventAnswerDeleted,
+			QuestionID: answer.QuestionID,
+			AnswerID:   id,
+			CreatedAt:  time.Now(),
+		})
+	})
+}
+
+// RestoreAnswer восстанавливает ответ. Ответ удаленного вопроса не восстанавливается:
+// возвращается ErrConflict, сначала нужно восстановить вопрос
+func (r *Repository) RestoreAnswer(ctx context.Context, id int) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		var answer model.Answer
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&answer, id).Error; err != nil {
+			return err
+		}
+
+		// Блокируем вопрос, чтобы его не удалили, пока восстанавливается ответ
+		var questions []model.Question
+		if err := tx.Select("id").Where("id = ?", answer.QuestionID).
+			Clauses(clause.Locking{Strength: "SHARE"}).
+			Limit(1).Find(&questions).Error; err != nil {
+			return err
+		}
+		if len(questions) == 0 {
+			return ErrConflict
+		}
+
+		return tx.Unscoped().Model(&answer).Update("deleted_at", nil).Error
+	})
 }
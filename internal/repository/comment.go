@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Подзапросы для подсчета комментариев к вопросу и ответу
+const (
+	questionCommentCountExpr = "(SELECT COUNT(*) FROM comments WHERE comments.entity_type = 'question' AND comments.entity_id = questions.id)"
+	answerCommentCountExpr   = "(SELECT COUNT(*) FROM comments WHERE comments.entity_type = 'answer' AND comments.entity_id = answers.id)"
+)
+
+// Методы для комментариев
+
+// CreateComment сохраняет комментарий и событие о нем в одной транзакции
+func (r *Repository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		event, err := commentEvent(tx, model.EventCommentCreated, comment)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		event.CreatedAt = comment.CreatedAt
+		return recordEvent(tx, event)
+	})
+}
+
+// GetCommentByID возвращает комментарий по ID
+func (r *Repository) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
+		return nil, dbError(err)
+	}
+	return &comment, nil
+}
+
+// ListComments возвращает страницу комментариев к записи от старых к новым
+func (r *Repository) ListComments(ctx context.Context, entityType string, entityID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	query := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+	if c := opts.After; c != nil {
+		query = query.Where("(created_at, id) > (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	comments := []model.Comment{}
+	if err := query.Order("created_at ASC, id ASC").Limit(opts.Limit + 1).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.CommentPage{Items: comments}
+	if len(comments) > opts.Limit {
+		page.Items = comments[:opts.Limit]
+		last := page.Items[opts.Limit-1]
+		page.NextCursor = model.Cursor{
+			Sort:      model.CommentSortOldest,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
+
+// DeleteComment окончательно удаляет комментарий и записывает событие в одной транзакции:
+// у комментариев нет истории правок и восстановления
+func (r *Repository) DeleteComment(ctx context.Context, id int) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		var comment model.Comment
+		if err := tx.First(&comment, id).Error; err != nil {
+			return err
+		}
+		event, err := commentEvent(tx, model.EventCommentDeleted, &comment)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		event.CreatedAt = time.Now()
+		return recordEvent(tx, event)
+	})
+}
+
+// commentEvent строит событие комментария. Событие относится к вопросу: комментарий
+// к ответу попадает в агрегат вопроса этого ответа, в том числе удаленного
+func commentEvent(tx *gorm.DB, eventType string, comment *model.Comment) (model.Event, error) {
+	event := model.Event{Type: eventType, QuestionID: comment.EntityID, Data: comment}
+	if comment.EntityType == model.CommentEntityAnswer {
+		var answer model.Answer
+		if err := tx.Unscoped().Select("id", "question_id").First(&answer, comment.EntityID).Error; err != nil {
+			return event, err
+		}
+		event.QuestionID = answer.QuestionID
+		event.AnswerID = answer.ID
+	}
+	return event, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound - запись не найдена; возвращается вместо gorm.ErrRecordNotFound
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict - запись нарушает уникальность или ссылочную целостность
+	ErrConflict = errors.New("record conflicts with existing data")
+)
+
+// Коды ошибок PostgreSQL
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// dbError переводит ошибки GORM и PostgreSQL в ошибки репозитория.
+// Остальные ошибки (например, недоступность базы) возвращаются как есть.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == pgUniqueViolation || pgErr.Code == pgForeignKeyViolation) {
+		return ErrConflict
+	}
+	return err
+}
+
+// transaction выполняет fn в транзакции, привязанной к ctx, и переводит ее ошибку в ошибку репозитория
+func (r *Repository) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return dbError(r.db.WithContext(ctx).Transaction(fn))
+}
@@ -1,18 +1,77 @@
 package repository
 
-import "qna-api/internal/model"
+import (
+	"context"
+	"time"
+
+	"qna-api/internal/model"
+)
 
 // RepositoryInterface определяет контракт для репозитория
 type RepositoryInterface interface {
+	// Unscoped возвращает репозиторий, включающий мягко удаленные записи
+	Unscoped() RepositoryInterface
+	PurgeDeleted(ctx context.Context, before time.Time) (*model.PurgeResult, error)
+
 	// Question methods
-	GetAllQuestions() ([]model.Question, error)
-	GetQuestionByID(id int) (*model.Question, error)
-	CreateQuestion(question *model.Question) error
-	DeleteQuestion(id int) error
+	ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error)
+	GetQuestionByID(ctx context.Context, id int) (*model.Question, error)
+	CreateQuestion(ctx context.Context, question *model.Question) error
+	FindSimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error)
+	UpdateQuestion(ctx context.Context, question *model.Question, revision *model.Revision) error
+	SetAcceptedAnswer(ctx context.Context, questionID int, answerID *int) error
+	SetQuestionLocked(ctx context.Context, id int, locked bool) error
+	DeleteQuestion(ctx context.Context, id int) error
+	RestoreQuestion(ctx context.Context, id int) error
 
 	// Answer methods
-	CreateAnswer(answer *model.Answer) error
-	GetAnswerByID(id int) (*model.Answer, error)
-	GetAnswersByQuestionID(questionID int) ([]model.Answer, error)
-	DeleteAnswer(id int) error
+	CreateAnswer(ctx context.Context, answer *model.Answer) error
+	GetAnswerByID(ctx context.Context, id int) (*model.Answer, error)
+	ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	ListAnswersByUserID(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	UpdateAnswer(ctx context.Context, answer *model.Answer, revision *model.Revision) error
+	SetAnswerLocked(ctx context.Context, id int, locked bool) error
+	DeleteAnswer(ctx context.Context, id int) error
+	RestoreAnswer(ctx context.Context, id int) error
+
+	// Comment methods
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	GetCommentByID(ctx context.Context, id int) (*model.Comment, error)
+	ListComments(ctx context.Context, entityType string, entityID int, opts model.CommentListOptions) (*model.CommentPage, error)
+	DeleteComment(ctx context.Context, id int) error
+
+	// Webhook methods
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+
+	// Outbox methods
+	ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error)
+	LatestEventID(ctx context.Context) (int64, error)
+	EventsAfter(ctx context.Context, afterID int64, limit int) ([]model.OutboxEvent, error)
+
+	// Revision methods
+	ListRevisions(ctx context.Context, entityType string, entityID int) ([]model.Revision, error)
+
+	// Tag methods
+	ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error)
+	GetTagByName(ctx context.Context, name string) (*model.Tag, error)
+	FindTags(ctx context.Context, names []string) ([]model.Tag, error)
+	ResolveTags(ctx context.Context, names []string) ([]model.Tag, error)
+	RenameTag(ctx context.Context, tag *model.Tag, name string) error
+	MergeTags(ctx context.Context, source, target *model.Tag) error
+
+	// Search methods
+	Search(ctx context.Context, opts model.SearchOptions) (*model.SearchPage, error)
+
+	// Vote methods
+	ApplyVote(ctx context.Context, vote *model.Vote) (int, error)
+
+	// User role methods
+	GetUserRole(ctx context.Context, userID string) (*model.UserRole, error)
+	SetUserRole(ctx context.Context, role *model.UserRole) error
 }
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// outboxLockKey - ключ advisory-блокировки: журнал событий разбирает один диспетчер за раз,
+// чтобы события агрегата публиковались в порядке записи
+const outboxLockKey = 7_301_017
+
+// recordEvent записывает доменное событие в журнал и ставит доставки вебхуков.
+// Вызывается внутри транзакции, изменяющей данные.
+func recordEvent(tx *gorm.DB, event model.Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if event.Tags == nil {
+		tags, err := questionTagNames(tx, event.QuestionID)
+		if err != nil {
+			return err
+		}
+		event.Tags = tags
+	}
+
+	if err := tx.Create(&model.OutboxEvent{
+		AggregateType: model.AggregateQuestion,
+		AggregateID:   event.QuestionID,
+		Type:          event.Type,
+		Payload:       event,
+		CreatedAt:     event.CreatedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return enqueueWebhookDeliveries(tx, event)
+}
+
+// questionTagNames возвращает имена тегов вопроса, в том числе удаленного
+func questionTagNames(tx *gorm.DB, questionID int) ([]string, error) {
+	names := []string{}
+	err := tx.Table("tags").
+		Joins("JOIN question_tags ON question_tags.tag_id = tags.id").
+		Where("question_tags.question_id = ?", questionID).
+		Order("tags.name ASC").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+// ProcessOutbox передает publish до limit неопубликованных событий в порядке записи
+// и отмечает опубликованными те, чьи ID он вернул. Пока publish работает, другие
+// экземпляры журнал не разбирают.
+func (r *Repository) ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error) {
+	published := 0
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var events []model.OutboxEvent
+		if err := tx.Where("published_at IS NULL").Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := publish(events)
+		if len(ids) == 0 {
+			return nil
+		}
+		published = len(ids)
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("published_at", time.Now()).Error
+	})
+	return published, err
+}
+
+// LatestEventID возвращает ID последнего события журнала или 0, если журнал пуст
+func (r *Repository) LatestEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.WithContext(ctx).Model(&model.OutboxEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// EventsAfter возвращает до limit событий журнала с ID больше afterID в порядке записи.
+// Журнал читают все экземпляры независимо, published_at не меняется.
+func (r *Repository) EventsAfter(ctx context.Context, afterID int64, limit int) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
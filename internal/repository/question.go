@@ -1,31 +1,249 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"qna-api/internal/model"
+
+	"gorm.io/gorm"
 )
 
+// Подзапрос для подсчета ответов на вопрос
+const answerCountExpr = "(SELECT COUNT(*) FROM answers WHERE answers.question_id = questions.id AND answers.deleted_at IS NULL)"
+
+// Столбцы вопроса вместе с вычисляемыми счетчиками
+const questionColumns = "questions.*, " + answerCountExpr + " AS answer_count, " +
+	questionCommentCountExpr + " AS comment_count"
+
 // Методы для вопросов
-func (r *Repository) GetAllQuestions() ([]model.Question, error) {
-	var questions []model.Question
-	result := r.db.Find(&questions)
-	return questions, result.Error
+func (r *Repository) ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error) {
+	query := r.db.WithContext(ctx).Model(&model.Question{}).
+		Select(questionColumns)
+
+	if opts.UserID != "" {
+		query = query.Where("questions.user_id = ?", opts.UserID)
+	}
+	if opts.Unanswered {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM answers WHERE answers.question_id = questions.id AND answers.deleted_at IS NULL)")
+	}
+	if opts.Unaccepted {
+		query = query.Where("questions.accepted_answer_id IS NULL")
+	}
+	if len(opts.TagIDs) > 0 {
+		tagged := r.db.Table("question_tags").Select("question_id").Where("tag_id IN ?", opts.TagIDs)
+		if opts.TagMatch == model.TagMatchAll {
+			tagged = tagged.Group("question_id").Having("COUNT(DISTINCT tag_id) = ?", len(opts.TagIDs))
+		}
+		query = query.Where("questions.id IN (?)", tagged)
+	}
+	if opts.CreatedAfter != nil {
+		query = query.Where("questions.created_at > ?", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		query = query.Where("questions.created_at < ?", *opts.CreatedBefore)
+	}
+
+	c := opts.After
+	switch opts.Sort {
+	case model.QuestionSortOldest:
+		if c != nil {
+			query = query.Where("(questions.created_at, questions.id) > (?, ?)", c.CreatedAt, c.ID)
+		}
+		query = query.Order("questions.created_at ASC, questions.id ASC")
+	case model.QuestionSortMostAnswered:
+		if c != nil {
+			query = query.Where("("+answerCountExpr+", questions.created_at, questions.id) < (?, ?, ?)",
+				c.Value, c.CreatedAt, c.ID)
+		}
+		query = query.Order("answer_count DESC, questions.created_at DESC, questions.id DESC")
+	case model.QuestionSortScore:
+		if c != nil {
+			query = query.Where("(questions.score, questions.created_at, questions.id) < (?, ?, ?)",
+				c.Value, c.CreatedAt, c.ID)
+		}
+		query = query.Order("questions.score DESC, questions.created_at DESC, questions.id DESC")
+	default:
+		if c != nil {
+			query = query.Where("(questions.created_at, questions.id) < (?, ?)", c.CreatedAt, c.ID)
+		}
+		query = query.Order("questions.created_at DESC, questions.id DESC")
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	questions := []model.Question{}
+	if err := query.Preload("Tags", orderTags).Limit(opts.Limit + 1).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.QuestionPage{Items: questions}
+	if len(questions) > opts.Limit {
+		page.Items = questions[:opts.Limit]
+		last := page.Items[opts.Limit-1]
+		value := last.AnswerCount
+		if opts.Sort == model.QuestionSortScore {
+			value = last.Score
+		}
+		page.NextCursor = model.Cursor{
+			Sort:      string(opts.Sort),
+			Value:     value,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return page, nil
 }
 
-func (r *Repository) GetQuestionByID(id int) (*model.Question, error) {
+func (r *Repository) GetQuestionByID(ctx context.Context, id int) (*model.Question, error) {
 	var question model.Question
-	result := r.db.Preload("Answers").First(&question, id)
+	result := r.db.WithContext(ctx).Select(questionColumns).
+		Preload("Tags", orderTags).
+		First(&question, id)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, dbError(result.Error)
 	}
 	return &question, nil
 }
 
-func (r *Repository) CreateQuestion(question *model.Question) error {
-	result := r.db.Create(question)
-	return result.Error
+// similarTitleQuery - запрос из слов заголовка, объединенных через ИЛИ,
+// чтобы находить вопросы с частично совпадающим заголовком
+const similarTitleQuery = "replace(plainto_tsquery(CAST(? AS regconfig), ?)::text, '&', '|')::tsquery"
+
+// FindSimilarQuestions ищет вопросы с похожими заголовками.
+// Учитывается только вес A поискового вектора, в который попадает заголовок.
+func (r *Repository) FindSimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error) {
+	rank := "ts_rank('{0,0,0,1}', search_vector, " + similarTitleQuery + ")"
+	questions := []model.Question{}
+	err := r.db.WithContext(ctx).Select(questionColumns).
+		Where(rank+" > 0", r.searchLanguage, title).
+		Order(gorm.Expr(rank+" DESC", r.searchLanguage, title)).
+		Order("questions.id DESC").
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// CreateQuestion сохраняет вопрос и событие о нем в одной транзакции
+func (r *Repository) CreateQuestion(ctx context.Context, question *model.Question) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventQuestionCreated,
+			QuestionID: question.ID,
+			Tags:       question.TagNames(),
+			Data:       question,
+			CreatedAt:  question.CreatedAt,
+		})
+	})
+}
+
+// UpdateQuestion сохраняет изменения вопроса, запись о правке и событие в одной транзакции
+func (r *Repository) UpdateQuestion(ctx context.Context, question *model.Question, revision *model.Revision) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(question).Select("Title", "Slug", "Body", "UpdatedAt").Updates(question).Error; err != nil {
+			return err
+		}
+		if _, ok := revision.Changes["tags"]; ok {
+			if err := tx.Model(question).Association("Tags").Replace(question.Tags); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventQuestionUpdated,
+			QuestionID: question.ID,
+			Tags:       question.TagNames(),
+			Data:       question,
+			CreatedAt:  question.UpdatedAt,
+		})
+	})
+}
+
+// orderTags упорядочивает теги вопроса по имени
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name ASC")
+}
+
+// SetAcceptedAnswer отмечает принятый ответ; nil снимает отметку.
+// Отметка не считается правкой вопроса и не меняет updated_at.
+func (r *Repository) SetAcceptedAnswer(ctx context.Context, questionID int, answerID *int) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&model.Question{}).Where("id = ?", questionID).
+			UpdateColumn("accepted_answer_id", answerID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if answerID == nil {
+			return nil
+		}
+
+		var answer model.Answer
+		if err := tx.First(&answer, *answerID).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventAnswerAccepted,
+			QuestionID: questionID,
+			AnswerID:   answer.ID,
+			Data:       &answer,
+			CreatedAt:  time.Now(),
+		})
+	})
 }
 
-func (r *Repository) DeleteQuestion(id int) error {
-	result := r.db.Delete(&model.Question{}, id)
-	return result.Error
+func (r *Repository) SetQuestionLocked(ctx context.Context, id int, locked bool) error {
+	result := r.db.WithContext(ctx).Model(&model.Question{}).Where("id = ?", id).Update("locked", locked)
+	if result.Error != nil {
+		return dbError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteQuestion мягко удаляет вопрос вместе с его ответами.
+// Ответы получают ту же отметку времени, чтобы восстановить их вместе с вопросом.
+func (r *Repository) DeleteQuestion(ctx context.Context, id int) error {
+	now := time.Now()
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Answer{}).Where("question_id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Question{}).Where("id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.Event{
+			Type:       model.EventQuestionDeleted,
+			QuestionID: id,
+			CreatedAt:  now,
+		})
+	})
+}
+
+// RestoreQuestion восстанавливает вопрос и ответы, удаленные вместе с ним
+func (r *Repository) RestoreQuestion(ctx context.Context, id int) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		var question model.Question
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&question, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&model.Answer{}).
+			Where("question_id = ? AND deleted_at = ?", id, question.DeletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&question).Update("deleted_at", nil).Error
+	})
 }
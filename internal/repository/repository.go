@@ -1,32 +1,165 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"qna-api/internal/model"
 
 	"gorm.io/gorm"
 )
 
 type Repository struct {
-	db *gorm.DB
+	db             *gorm.DB
+	searchLanguage string
+}
+
+// Option настраивает репозиторий
+type Option func(*Repository)
+
+// WithSearchLanguage задает конфигурацию текстового поиска PostgreSQL
+func WithSearchLanguage(language string) Option {
+	return func(r *Repository) {
+		r.searchLanguage = language
+	}
 }
 
 // NewRepository создает новый репозиторий
-func NewRepository(db *gorm.DB) RepositoryInterface { // Возвращаем интерфейс
-	return &Repository{db: db}
+func NewRepository(db *gorm.DB, opts ...Option) RepositoryInterface { // Возвращаем интерфейс
+	r := &Repository{db: db, searchLanguage: DefaultSearchLanguage}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Unscoped возвращает репозиторий, который видит мягко удаленные записи
+func (r *Repository) Unscoped() RepositoryInterface {
+	return &Repository{db: r.db.Unscoped().Session(&gorm.Session{}), searchLanguage: r.searchLanguage}
+}
+
+// PurgeDeleted окончательно удаляет записи, мягко удаленные раньше before, вместе с
+// ответами удаляемых вопросов. Комментарии и голоса не связаны внешним ключом и удаляются
+// явно, до родительских записей, пока подзапросы еще находят их id. Журнал правок
+// только дополняется, поэтому правки удаленных записей остаются в нем.
+func (r *Repository) PurgeDeleted(ctx context.Context, before time.Time) (*model.PurgeResult, error) {
+	result := &model.PurgeResult{}
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		purgedQuestions := tx.Unscoped().Model(&model.Question{}).Select("id").Where("deleted_at < ?", before)
+		purgedAnswers := tx.Unscoped().Model(&model.Answer{}).Select("id").
+			Where("deleted_at < ? OR question_id IN (?)", before, purgedQuestions)
+		purgeDependents := func(value interface{}, questionType, answerType string) (int64, error) {
+			deleted := tx.Where("(entity_type = ? AND entity_id IN (?)) OR (entity_type = ? AND entity_id IN (?))",
+				questionType, purgedQuestions, answerType, purgedAnswers).Delete(value)
+			return deleted.RowsAffected, deleted.Error
+		}
+
+		var err error
+		if result.Comments, err = purgeDependents(&model.Comment{},
+			model.CommentEntityQuestion, model.CommentEntityAnswer); err != nil {
+			return err
+		}
+		if result.Votes, err = purgeDependents(&model.Vote{},
+			model.VoteEntityQuestion, model.VoteEntityAnswer); err != nil {
+			return err
+		}
+
+		answers := tx.Unscoped().Where("deleted_at < ? OR question_id IN (?)", before, purgedQuestions).
+			Delete(&model.Answer{})
+		if answers.Error != nil {
+			return answers.Error
+		}
+		result.Answers = answers.RowsAffected
+
+		questions := tx.Unscoped().Where("deleted_at < ?", before).Delete(&model.Question{})
+		if questions.Error != nil {
+			return questions.Error
+		}
+		result.Questions = questions.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // Интерфейсы вопросов
 type IQuestionRepository interface {
-	GetAllQuestions() ([]model.Question, error)
-	GetQuestionByID(id int) (*model.Question, error)
-	CreateQuestion(question *model.Question) error
-	DeleteQuestion(id int) error
+	ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error)
+	GetQuestionByID(ctx context.Context, id int) (*model.Question, error)
+	CreateQuestion(ctx context.Context, question *model.Question) error
+	FindSimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error)
+	UpdateQuestion(ctx context.Context, question *model.Question, revision *model.Revision) error
+	SetAcceptedAnswer(ctx context.Context, questionID int, answerID *int) error
+	SetQuestionLocked(ctx context.Context, id int, locked bool) error
+	DeleteQuestion(ctx context.Context, id int) error
+	RestoreQuestion(ctx context.Context, id int) error
 }
 
 // Интерфейсы ответов
 type IAnswerRepository interface {
-	CreateAnswer(answer *model.Answer) error
-	GetAnswerByID(id int) (*model.Answer, error)
-	GetAnswersByQuestionID(questionID int) ([]model.Answer, error)
-	DeleteAnswer(id int) error
+	CreateAnswer(ctx context.Context, answer *model.Answer) error
+	GetAnswerByID(ctx context.Context, id int) (*model.Answer, error)
+	ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	ListAnswersByUserID(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	UpdateAnswer(ctx context.Context, answer *model.Answer, revision *model.Revision) error
+	SetAnswerLocked(ctx context.Context, id int, locked bool) error
+	DeleteAnswer(ctx context.Context, id int) error
+	RestoreAnswer(ctx context.Context, id int) error
+}
+
+// Интерфейсы комментариев
+type ICommentRepository interface {
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	GetCommentByID(ctx context.Context, id int) (*model.Comment, error)
+	ListComments(ctx context.Context, entityType string, entityID int, opts model.CommentListOptions) (*model.CommentPage, error)
+	DeleteComment(ctx context.Context, id int) error
+}
+
+// Интерфейсы вебхуков
+type IWebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// Интерфейсы журнала событий
+type IOutboxRepository interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error)
+}
+
+// Интерфейсы журнала правок
+type IRevisionRepository interface {
+	ListRevisions(ctx context.Context, entityType string, entityID int) ([]model.Revision, error)
+}
+
+// Интерфейсы голосов
+type IVoteRepository interface {
+	ApplyVote(ctx context.Context, vote *model.Vote) (int, error)
+}
+
+// Интерфейсы тегов
+type ITagRepository interface {
+	ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error)
+	GetTagByName(ctx context.Context, name string) (*model.Tag, error)
+	FindTags(ctx context.Context, names []string) ([]model.Tag, error)
+	ResolveTags(ctx context.Context, names []string) ([]model.Tag, error)
+	RenameTag(ctx context.Context, tag *model.Tag, name string) error
+	MergeTags(ctx context.Context, source, target *model.Tag) error
+}
+
+// Интерфейсы поиска
+type ISearchRepository interface {
+	Search(ctx context.Context, opts model.SearchOptions) (*model.SearchPage, error)
+}
+
+// Интерфейсы ролей пользователей
+type IUserRoleRepository interface {
+	GetUserRole(ctx context.Context, userID string) (*model.UserRole, error)
+	SetUserRole(ctx context.Context, role *model.UserRole) error
 }
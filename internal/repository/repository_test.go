@@ -1,13 +1,21 @@
 package repository
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"qna-api/internal/model"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func setupTestDB() *gorm.DB {
@@ -20,16 +28,29 @@ func setupTestDB() *gorm.DB {
 	}
 
 	// Auto migrate models
-	db.AutoMigrate(&model.Question{}, &model.Answer{})
+	db.AutoMigrate(&model.Question{}, &model.Answer{}, &model.Revision{}, &model.UserRole{}, &model.Vote{}, &model.Tag{}, &model.TagSynonym{}, &model.Comment{}, &model.Webhook{}, &model.WebhookDelivery{}, &model.OutboxEvent{})
+	searchSchema, _ := SearchSchema(DefaultSearchLanguage)
+	for _, statement := range searchSchema {
+		db.Exec(statement)
+	}
 
 	// Очищаем таблицы перед тестом
 	db.Exec("TRUNCATE TABLE answers CASCADE")
 	db.Exec("TRUNCATE TABLE questions CASCADE")
+	db.Exec("TRUNCATE TABLE revisions")
+	db.Exec("TRUNCATE TABLE user_roles")
+	db.Exec("TRUNCATE TABLE votes")
+	db.Exec("TRUNCATE TABLE tags CASCADE")
+	db.Exec("TRUNCATE TABLE tag_synonyms")
+	db.Exec("TRUNCATE TABLE comments")
+	db.Exec("TRUNCATE TABLE webhooks CASCADE")
+	db.Exec("TRUNCATE TABLE events")
 
 	return db
 }
 
 func TestCreateAndGetQuestion(t *testing.T) {
+	ctx := context.Background()
 	db := setupTestDB()
 	if db == nil {
 		t.Skip("PostgreSQL not available, skipping test")
@@ -39,18 +60,52 @@ func TestCreateAndGetQuestion(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Test create question
-	question := &model.Question{Text: "Test question"}
-	err := repo.CreateQuestion(question)
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	err := repo.CreateQuestion(ctx, question)
 	assert.NoError(t, err)
 	assert.NotZero(t, question.ID)
 
 	// Test get question
-	found, err := repo.GetQuestionByID(question.ID)
+	found, err := repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test question", found.Title)
+}
+
+func TestUpdateQuestionWithRevision(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Old text", Body: "Old text"}
+	repo.CreateQuestion(ctx, question)
+
+	question.Body = "New text"
+	revision := &model.Revision{
+		EntityType: model.RevisionEntityQuestion,
+		EntityID:   question.ID,
+		EditorID:   "user-123",
+		Changes:    map[string]model.FieldChange{"body": {Old: "Old text", New: "New text"}},
+	}
+	err := repo.UpdateQuestion(ctx, question, revision)
+	assert.NoError(t, err)
+
+	found, err := repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "New text", found.Body)
+
+	revisions, err := repo.ListRevisions(ctx, model.RevisionEntityQuestion, question.ID)
 	assert.NoError(t, err)
-	assert.Equal(t, "Test question", found.Text)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, "Old text", revisions[0].Changes["body"].Old)
 }
 
 func TestCreateAnswer(t *testing.T) {
+	ctx := context.Background()
 	db := setupTestDB()
 	if db == nil {
 		t.Skip("PostgreSQL not available, skipping test")
@@ -60,8 +115,8 @@ func TestCreateAnswer(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Create question first
-	question := &model.Question{Text: "Test question"}
-	repo.CreateQuestion(question)
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
 
 	// Create answer
 	answer := &model.Answer{
@@ -70,12 +125,257 @@ func TestCreateAnswer(t *testing.T) {
 		Text:       "Test answer",
 	}
 
-	err := repo.CreateAnswer(answer)
+	err := repo.CreateAnswer(ctx, answer)
 	assert.NoError(t, err)
 	assert.NotZero(t, answer.ID)
 }
 
+func TestListAnswersPagination(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+
+	for _, text := range []string{"First", "Second", "Third"} {
+		repo.CreateAnswer(ctx, &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: text})
+	}
+
+	opts := model.AnswerListOptions{Limit: 2, Sort: model.AnswerSortOldest}
+	page, err := repo.ListAnswers(ctx, question.ID, opts)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	opts.After, err = model.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+
+	page, err = repo.ListAnswers(ctx, question.ID, opts)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "Third", page.Items[0].Text)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestListByUser(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	mine := &model.Question{UserID: "user-123", Title: "My question", Body: "My question"}
+	repo.CreateQuestion(ctx, mine)
+	other := &model.Question{UserID: "user-456", Title: "Other question", Body: "Other question"}
+	repo.CreateQuestion(ctx, other)
+
+	repo.CreateAnswer(ctx, &model.Answer{QuestionID: other.ID, UserID: "user-123", Text: "My answer"})
+	repo.CreateAnswer(ctx, &model.Answer{QuestionID: mine.ID, UserID: "user-456", Text: "Other answer"})
+
+	questions, err := repo.ListQuestions(ctx, model.QuestionListOptions{Limit: 10, UserID: "user-123"})
+	assert.NoError(t, err)
+	assert.Len(t, questions.Items, 1)
+	assert.Equal(t, "My question", questions.Items[0].Title)
+
+	answers, err := repo.ListAnswersByUserID(ctx, "user-123", model.AnswerListOptions{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, answers.Items, 1)
+	assert.Equal(t, "My answer", answers.Items[0].Text)
+}
+
+func TestApplyVote(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+
+	vote := func(userID string, value int) int {
+		score, err := repo.ApplyVote(ctx, &model.Vote{
+			EntityType: model.VoteEntityQuestion,
+			EntityID:   question.ID,
+			UserID:     userID,
+			Value:      value,
+		})
+		assert.NoError(t, err)
+		return score
+	}
+
+	assert.Equal(t, 1, vote("user-1", 1))
+	assert.Equal(t, 2, vote("user-2", 1))
+	// Повторный голос заменяет предыдущий
+	assert.Equal(t, 0, vote("user-1", -1))
+	// Нулевой голос отменяет голос
+	assert.Equal(t, 1, vote("user-1", 0))
+
+	found, err := repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Score)
+
+	var count int64
+	db.Model(&model.Vote{}).Where("entity_id = ?", question.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAcceptedAnswer(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	repo.CreateAnswer(ctx, answer)
+	repo.CreateQuestion(ctx, &model.Question{Title: "Unanswered question", Body: "Unanswered question"})
+
+	assert.NoError(t, repo.SetAcceptedAnswer(ctx, question.ID, &answer.ID))
+
+	found, err := repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, answer.ID, *found.AcceptedAnswerID)
+
+	page, err := repo.ListQuestions(ctx, model.QuestionListOptions{Limit: 10, Unaccepted: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "Unanswered question", page.Items[0].Title)
+
+	page, err = repo.ListQuestions(ctx, model.QuestionListOptions{Limit: 10, Unanswered: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+
+	// Удаление принятого ответа снимает отметку
+	assert.NoError(t, repo.DeleteAnswer(ctx, answer.ID))
+	found, err = repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, found.AcceptedAnswerID)
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "How do I create a GIN index in PostgreSQL?", Body: "How do I create a GIN index in PostgreSQL?"}
+	repo.CreateQuestion(ctx, question)
+	repo.CreateAnswer(ctx, &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Use CREATE INDEX ... USING GIN"})
+	repo.CreateQuestion(ctx, &model.Question{Title: "Unrelated question about Go", Body: "Unrelated question about Go"})
+
+	page, err := repo.Search(ctx, model.SearchOptions{Query: "gin index", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.Contains(t, page.Items[0].Snippet, "<mark>")
+
+	cursor, err := model.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+
+	page, err = repo.Search(ctx, model.SearchOptions{Query: "gin index", Limit: 1, After: cursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = repo.Search(ctx, model.SearchOptions{Query: "gin", Type: model.SearchTypeAnswer, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, question.ID, page.Items[0].QuestionID)
+}
+
+func TestFindSimilarQuestions(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	similar := &model.Question{Title: "How to configure Go modules", Body: "Details"}
+	repo.CreateQuestion(ctx, similar)
+	// Совпадение только в теле не учитывается
+	repo.CreateQuestion(ctx, &model.Question{Title: "Unrelated", Body: "Go modules are mentioned here"})
+
+	questions, err := repo.FindSimilarQuestions(ctx, "go modules proxy", 5)
+	assert.NoError(t, err)
+	assert.Len(t, questions, 1)
+	assert.Equal(t, similar.ID, questions[0].ID)
+}
+
+func TestSearchSchema_InvalidLanguage(t *testing.T) {
+	_, err := SearchSchema("english'; DROP TABLE questions; --")
+	assert.Error(t, err)
+
+	statements, err := SearchSchema("russian")
+	assert.NoError(t, err)
+	assert.Len(t, statements, 5)
+	assert.Contains(t, statements[1], "setweight")
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	tags, err := repo.ResolveTags(ctx, []string{"go", "golang", "postgres"})
+	assert.NoError(t, err)
+	assert.Len(t, tags, 3)
+
+	repo.CreateQuestion(ctx, &model.Question{Title: "Go and Postgres", Body: "Go and Postgres", Tags: tags[:2]})
+	repo.CreateQuestion(ctx, &model.Question{Title: "Only Go", Body: "Only Go", Tags: tags[:1]})
+
+	// golang объединяется с go и становится синонимом
+	golang, _ := repo.GetTagByName(ctx, "golang")
+	goTag, _ := repo.GetTagByName(ctx, "go")
+	assert.NoError(t, repo.MergeTags(ctx, golang, goTag))
+
+	resolved, err := repo.ResolveTags(ctx, []string{"golang"})
+	assert.NoError(t, err)
+	assert.Len(t, resolved, 1)
+	assert.Equal(t, "go", resolved[0].Name)
+
+	list, err := repo.ListTags(ctx, model.TagListOptions{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "go", list[0].Name)
+	assert.Equal(t, 2, list[0].QuestionCount)
+
+	page, err := repo.ListQuestions(ctx, model.QuestionListOptions{Limit: 10, TagIDs: []int{goTag.ID}, TagMatch: model.TagMatchAll})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.Items[0].Tags)
+}
+
 func TestCascadeDelete(t *testing.T) {
+	ctx := context.Background()
 	db := setupTestDB()
 	if db == nil {
 		t.Skip("PostgreSQL not available, skipping test")
@@ -85,21 +385,659 @@ func TestCascadeDelete(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Create question with answers
-	question := &model.Question{Text: "Test question"}
-	repo.CreateQuestion(question)
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
 
 	answer := &model.Answer{
 		QuestionID: question.ID,
 		UserID:     "user-123",
 		Text:       "Test answer",
 	}
-	repo.CreateAnswer(answer)
+	repo.CreateAnswer(ctx, answer)
 
 	// Delete question
-	err := repo.DeleteQuestion(question.ID)
+	err := repo.DeleteQuestion(ctx, question.ID)
 	assert.NoError(t, err)
 
 	// Verify answer is also deleted
-	_, err = repo.GetAnswerByID(answer.ID)
+	_, err = repo.GetAnswerByID(ctx, answer.ID)
 	assert.Error(t, err)
 }
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	repo.CreateAnswer(ctx, answer)
+
+	err := repo.DeleteQuestion(ctx, question.ID)
+	assert.NoError(t, err)
+
+	// Удаленные записи скрыты, но доступны через Unscoped
+	_, err = repo.GetQuestionByID(ctx, question.ID)
+	assert.Error(t, err)
+	_, err = repo.Unscoped().GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+
+	err = repo.RestoreQuestion(ctx, question.ID)
+	assert.NoError(t, err)
+
+	_, err = repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	_, err = repo.GetAnswerByID(ctx, answer.ID)
+	assert.NoError(t, err)
+
+	// Повторное восстановление невозможно
+	err = repo.RestoreQuestion(ctx, question.ID)
+	assert.Error(t, err)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	repo.CreateAnswer(ctx, answer)
+	answer.Text = "Edited answer"
+	assert.NoError(t, repo.UpdateAnswer(ctx, answer, &model.Revision{
+		EntityType: model.RevisionEntityAnswer, EntityID: answer.ID, EditorID: "user-123",
+		Changes: map[string]model.FieldChange{"text": {Old: "Test answer", New: "Edited answer"}},
+	}))
+	_, err := repo.ApplyVote(ctx, &model.Vote{EntityType: model.VoteEntityAnswer, EntityID: answer.ID, UserID: "user-1", Value: 1})
+	assert.NoError(t, err)
+	_, err = repo.ApplyVote(ctx, &model.Vote{EntityType: model.VoteEntityQuestion, EntityID: question.ID, UserID: "user-1", Value: 1})
+	assert.NoError(t, err)
+	repo.DeleteQuestion(ctx, question.ID)
+
+	// Ответ удаляется вместе с вопросом и учитывается отдельно
+	result, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Questions)
+	assert.Equal(t, int64(1), result.Answers)
+	assert.Equal(t, int64(2), result.Votes)
+
+	_, err = repo.Unscoped().GetQuestionByID(ctx, question.ID)
+	assert.Error(t, err)
+
+	// Голоса удаляются, а журнал правок сохраняется
+	var votes, revisions int64
+	db.Model(&model.Vote{}).Count(&votes)
+	db.Model(&model.Revision{}).Count(&revisions)
+	assert.Zero(t, votes)
+	assert.Equal(t, int64(1), revisions)
+}
+
+func TestComments(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	repo.CreateAnswer(ctx, answer)
+
+	for _, text := range []string{"First", "Second", "Third"} {
+		assert.NoError(t, repo.CreateComment(ctx, &model.Comment{
+			EntityType: model.CommentEntityQuestion, EntityID: question.ID, UserID: "user-123", Text: text,
+		}))
+	}
+	repo.CreateComment(ctx, &model.Comment{EntityType: model.CommentEntityAnswer, EntityID: answer.ID, UserID: "user-123", Text: "On answer"})
+
+	page, err := repo.ListComments(ctx, model.CommentEntityQuestion, question.ID, model.CommentListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	after, _ := model.DecodeCursor(page.NextCursor)
+	page, err = repo.ListComments(ctx, model.CommentEntityQuestion, question.ID, model.CommentListOptions{Limit: 2, After: after})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "Third", page.Items[0].Text)
+
+	found, err := repo.GetQuestionByID(ctx, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, found.CommentCount)
+
+	foundAnswer, err := repo.GetAnswerByID(ctx, answer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, foundAnswer.CommentCount)
+
+	assert.NoError(t, repo.DeleteComment(ctx, page.Items[0].ID))
+	assert.Error(t, repo.DeleteComment(ctx, page.Items[0].ID))
+
+	// Комментарии удаляются вместе с окончательно удаленным вопросом
+	repo.DeleteQuestion(ctx, question.ID)
+	_, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	var remaining int64
+	db.Model(&model.Comment{}).Count(&remaining)
+	assert.Zero(t, remaining)
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	all := &model.Webhook{URL: "https://example.com/all", Events: []string{}, Secret: "0123456789abcdef"}
+	answers := &model.Webhook{URL: "https://example.com/answers", Events: []string{model.EventAnswerCreated}, Secret: "0123456789abcdef"}
+	assert.NoError(t, repo.CreateWebhook(ctx, all))
+	assert.NoError(t, repo.CreateWebhook(ctx, answers))
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	repo.CreateQuestion(ctx, question)
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	assert.NoError(t, repo.CreateAnswer(ctx, answer))
+
+	// question.created - только для вебхука без фильтра, answer.created - для обоих
+	page, err := repo.ListWebhookDeliveries(ctx, all.ID, model.DeliveryListOptions{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, model.EventAnswerCreated, page.Items[0].EventType)
+
+	page, err = repo.ListWebhookDeliveries(ctx, answers.ID, model.DeliveryListOptions{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, answer.ID, page.Items[0].Payload.AnswerID)
+
+	// Забранные доставки не выдаются повторно до истечения аренды
+	claimed, err := repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 3)
+	assert.NotNil(t, claimed[0].Webhook)
+
+	again, err := repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, again)
+
+	claimed[0].Status = model.DeliveryDelivered
+	claimed[0].Attempts = 1
+	assert.NoError(t, repo.SaveWebhookDelivery(ctx, &claimed[0]))
+
+	page, err = repo.ListWebhookDeliveries(ctx, claimed[0].WebhookID, model.DeliveryListOptions{Limit: 10, Status: model.DeliveryDelivered})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+
+	// Доставки удаляются вместе с вебхуком
+	assert.NoError(t, repo.DeleteWebhook(ctx, all.ID))
+	var remaining int64
+	db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", all.ID).Count(&remaining)
+	assert.Zero(t, remaining)
+}
+
+func TestEventOutbox(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	tags, _ := repo.ResolveTags(ctx, []string{"go"})
+	question := &model.Question{Title: "Test question", Body: "Test question", Tags: tags}
+	assert.NoError(t, repo.CreateQuestion(ctx, question))
+	answer := &model.Answer{QuestionID: question.ID, UserID: "user-123", Text: "Test answer"}
+	assert.NoError(t, repo.CreateAnswer(ctx, answer))
+	assert.NoError(t, repo.SetAcceptedAnswer(ctx, question.ID, &answer.ID))
+	assert.NoError(t, repo.DeleteAnswer(ctx, answer.ID))
+	assert.NoError(t, repo.DeleteQuestion(ctx, question.ID))
+
+	// Неудачное изменение не оставляет события: транзакция откатывается целиком
+	assert.Error(t, repo.CreateAnswer(ctx, &model.Answer{QuestionID: 999999, Text: "Orphan"}))
+
+	var types []string
+	var seen []model.OutboxEvent
+	n, err := repo.ProcessOutbox(ctx, 100, func(events []model.OutboxEvent) []int64 {
+		seen = events
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return []int64{events[0].ID}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{
+		model.EventQuestionCreated,
+		model.EventAnswerCreated,
+		model.EventAnswerAccepted,
+		model.EventAnswerDeleted,
+		model.EventQuestionDeleted,
+	}, types)
+	for _, event := range seen {
+		assert.Equal(t, question.ID, event.AggregateID)
+		assert.Equal(t, []string{"go"}, event.Payload.Tags)
+	}
+
+	// Следующий проход получает только неопубликованные события
+	n, err = repo.ProcessOutbox(ctx, 100, func(events []model.OutboxEvent) []int64 {
+		assert.Len(t, events, 4)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestEventsAfter(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+	latest, err := repo.LatestEventID(ctx)
+	assert.NoError(t, err)
+
+	question := &model.Question{Title: "Test question", Body: "Test question"}
+	assert.NoError(t, repo.CreateQuestion(ctx, question))
+	assert.NoError(t, repo.CreateAnswer(ctx, &model.Answer{QuestionID: question.ID, Text: "Test answer"}))
+
+	// Чтение журнала не зависит от published_at и не меняет его
+	events, err := repo.EventsAfter(ctx, latest, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, model.EventQuestionCreated, events[0].Type)
+		assert.Less(t, events[0].ID, events[1].ID)
+		assert.Nil(t, events[1].PublishedAt)
+	}
+
+	events, err = repo.EventsAfter(ctx, latest+1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	newest, err := repo.LatestEventID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, events[0].ID, newest)
+}
+
+func TestErrorMapping(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB()
+	if db == nil {
+		t.Skip("PostgreSQL not available, skipping test")
+		return
+	}
+
+	repo := NewRepository(db)
+
+	_, err := repo.GetQuestionByID(ctx, 999999)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Ответ к несуществующему вопросу - ErrNotFound, а не внутренняя ошибка
+	err = repo.CreateAnswer(ctx, &model.Answer{QuestionID: 999999, UserID: "user-123", Text: "Orphan"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, repo.DeleteComment(ctx, 999999), ErrNotFound)
+	assert.ErrorIs(t, repo.SetQuestionLocked(ctx, 999999, true), ErrNotFound)
+
+	tags, _ := repo.ResolveTags(ctx, []string{"go", "rust"})
+	assert.ErrorIs(t, repo.RenameTag(ctx, &tags[0], tags[1].Name), ErrConflict)
+}
+
+// newMockRepository создает репозиторий поверх sqlmock, чтобы проверять запросы без PostgreSQL.
+// Ожидания сверяются в конце теста.
+func newMockRepository(t *testing.T) (*Repository, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return NewRepository(db).(*Repository), mock
+}
+
+// eventPayload сравнивает payload записываемого в журнал события
+type eventPayload struct {
+	Type       string
+	QuestionID int
+	AnswerID   int
+}
+
+func (p eventPayload) Match(v driver.Value) bool {
+	var raw []byte
+	switch value := v.(type) {
+	case string:
+		raw = []byte(value)
+	case []byte:
+		raw = value
+	default:
+		return false
+	}
+	var event model.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return false
+	}
+	return event.Type == p.Type && event.QuestionID == p.QuestionID && event.AnswerID == p.AnswerID
+}
+
+// expectEvent ожидает запись события в журнал внутри транзакции: загрузку тегов вопроса,
+// строку events и поиск вебхуков для доставок
+func expectEvent(mock sqlmock.Sqlmock, payload eventPayload) {
+	mock.ExpectQuery(`SELECT "tags"."name" FROM "tags"`).WithArgs(payload.QuestionID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("go"))
+	mock.ExpectQuery(`INSERT INTO "events"`).
+		WithArgs(model.AggregateQuestion, payload.QuestionID, payload.Type, payload, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "webhooks"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func idRow(id int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id"}).AddRow(id)
+}
+
+func TestMutationsRecordEvents(t *testing.T) {
+	ctx := context.Background()
+	answerID := 5
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		run    func(repo *Repository) error
+	}{
+		{
+			name: "create question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "questions"`).WillReturnRows(idRow(1))
+				expectEvent(mock, eventPayload{Type: model.EventQuestionCreated, QuestionID: 1})
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateQuestion(ctx, &model.Question{Title: "Title", Body: "Body"})
+			},
+		},
+		{
+			name: "update question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "questions" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(idRow(1))
+				expectEvent(mock, eventPayload{Type: model.EventQuestionUpdated, QuestionID: 1})
+			},
+			run: func(repo *Repository) error {
+				return repo.UpdateQuestion(ctx, &model.Question{ID: 1, Title: "Title", Body: "Body"},
+					&model.Revision{EntityType: model.RevisionEntityQuestion, EntityID: 1, Changes: map[string]model.FieldChange{"body": {}}})
+			},
+		},
+		{
+			name: "delete question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "answers" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`UPDATE "questions" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEvent(mock, eventPayload{Type: model.EventQuestionDeleted, QuestionID: 1})
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteQuestion(ctx, 1)
+			},
+		},
+		{
+			name: "accept answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "questions" SET "accepted_answer_id"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT \* FROM "answers"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(answerID, 1))
+				expectEvent(mock, eventPayload{Type: model.EventAnswerAccepted, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.SetAcceptedAnswer(ctx, 1, &answerID)
+			},
+		},
+		{
+			name: "create answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "questions"`).WillReturnRows(idRow(1))
+				mock.ExpectQuery(`INSERT INTO "answers"`).WillReturnRows(idRow(answerID))
+				expectEvent(mock, eventPayload{Type: model.EventAnswerCreated, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateAnswer(ctx, &model.Answer{QuestionID: 1, Text: "Answer"})
+			},
+		},
+		{
+			name: "update answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "answers" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO "revisions"`).WillReturnRows(idRow(1))
+				expectEvent(mock, eventPayload{Type: model.EventAnswerUpdated, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.UpdateAnswer(ctx, &model.Answer{ID: answerID, QuestionID: 1, Text: "Answer"},
+					&model.Revision{EntityType: model.RevisionEntityAnswer, EntityID: answerID, Changes: map[string]model.FieldChange{"text": {}}})
+			},
+		},
+		{
+			name: "delete answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "answers"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(answerID, 1))
+				mock.ExpectExec(`UPDATE "questions" SET "accepted_answer_id"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE "answers" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEvent(mock, eventPayload{Type: model.EventAnswerDeleted, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteAnswer(ctx, answerID)
+			},
+		},
+		{
+			name: "comment question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "comments"`).WillReturnRows(idRow(3))
+				expectEvent(mock, eventPayload{Type: model.EventCommentCreated, QuestionID: 1})
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateComment(ctx, &model.Comment{EntityType: model.CommentEntityQuestion, EntityID: 1, Text: "Comment"})
+			},
+		},
+		{
+			name: "comment answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				// Комментарий к ответу относится к агрегату вопроса этого ответа
+				mock.ExpectQuery(`SELECT "id","question_id" FROM "answers"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(answerID, 1))
+				mock.ExpectQuery(`INSERT INTO "comments"`).WillReturnRows(idRow(3))
+				expectEvent(mock, eventPayload{Type: model.EventCommentCreated, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateComment(ctx, &model.Comment{EntityType: model.CommentEntityAnswer, EntityID: answerID, Text: "Comment"})
+			},
+		},
+		{
+			name: "delete comment",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "comments"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "entity_type", "entity_id"}).AddRow(3, model.CommentEntityAnswer, answerID))
+				mock.ExpectQuery(`SELECT "id","question_id" FROM "answers"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(answerID, 1))
+				mock.ExpectExec(`DELETE FROM "comments"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEvent(mock, eventPayload{Type: model.EventCommentDeleted, QuestionID: 1, AnswerID: answerID})
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteComment(ctx, 3)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockRepository(t)
+			// Событие пишется в той же транзакции, что и изменение
+			mock.ExpectBegin()
+			tt.expect(mock)
+			mock.ExpectCommit()
+
+			assert.NoError(t, tt.run(repo))
+		})
+	}
+}
+
+func TestFailedMutationsRecordNoEvents(t *testing.T) {
+	ctx := context.Background()
+	answerID := 5
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		run    func(repo *Repository) error
+	}{
+		{
+			name: "create question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "questions"`).WillReturnError(errors.New("connection reset"))
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateQuestion(ctx, &model.Question{Title: "Title", Body: "Body"})
+			},
+		},
+		{
+			name: "create answer to missing question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "questions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			run: func(repo *Repository) error {
+				return repo.CreateAnswer(ctx, &model.Answer{QuestionID: 999, Text: "Answer"})
+			},
+		},
+		{
+			name: "accept answer of missing question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "questions" SET "accepted_answer_id"`).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			run: func(repo *Repository) error {
+				return repo.SetAcceptedAnswer(ctx, 999, &answerID)
+			},
+		},
+		{
+			name: "delete missing answer",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "answers"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteAnswer(ctx, 999)
+			},
+		},
+		{
+			name: "delete missing comment",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "comments"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteComment(ctx, 999)
+			},
+		},
+		{
+			name: "delete question",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE "answers" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE "questions" SET "deleted_at"`).WillReturnError(errors.New("connection reset"))
+			},
+			run: func(repo *Repository) error {
+				return repo.DeleteQuestion(ctx, 1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockRepository(t)
+			// Транзакция откатывается до записи события; лишний INSERT INTO events провалил бы ожидания
+			mock.ExpectBegin()
+			tt.expect(mock)
+			mock.ExpectRollback()
+
+			assert.Error(t, tt.run(repo))
+		})
+	}
+}
+
+func TestUnacceptRecordsNoEvent(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	// Снятие отметки о принятом ответе событий не создает
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "questions" SET "accepted_answer_id"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SetAcceptedAnswer(context.Background(), 1, nil))
+}
+
+func TestPurgeDeletedCascade(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	before := time.Now()
+
+	// Зависимые записи удаляются до ответов, ответы удаленных вопросов - до вопросов
+	purgedAnswers := `SELECT "id" FROM "answers" WHERE deleted_at < \$\d+ OR question_id IN \(SELECT "id" FROM "questions" WHERE deleted_at < \$\d+\)`
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "comments" WHERE .*` + purgedAnswers).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`DELETE FROM "votes" WHERE .*` + purgedAnswers).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM "answers" WHERE deleted_at < \$1 OR question_id IN \(SELECT "id" FROM "questions" WHERE deleted_at < \$2\)`).
+		WithArgs(before, before).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(`DELETE FROM "questions" WHERE deleted_at < \$1`).
+		WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := repo.PurgeDeleted(context.Background(), before)
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.PurgeResult{Questions: 1, Answers: 5, Comments: 4, Votes: 3}, result)
+}
+
+func TestRestoreAnswer_QuestionDeleted(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	// Вопрос удален: выборка с блокировкой пуста, ответ остается удаленным
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "answers" WHERE deleted_at IS NOT NULL AND "answers"."id" = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(1, 2))
+	mock.ExpectQuery(`SELECT "id" FROM "questions" WHERE id = \$1 AND "questions"."deleted_at" IS NULL LIMIT 1 FOR SHARE`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := repo.RestoreAnswer(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestRestoreAnswer(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "answers" WHERE deleted_at IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id"}).AddRow(1, 2))
+	mock.ExpectQuery(`SELECT "id" FROM "questions" .* FOR SHARE`).WillReturnRows(idRow(2))
+	mock.ExpectExec(`UPDATE "answers" SET "deleted_at"=\$1,"updated_at"=\$2 WHERE "id" = \$3`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RestoreAnswer(context.Background(), 1))
+}
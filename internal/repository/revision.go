@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"qna-api/internal/model"
+)
+
+// Методы для журнала правок
+func (r *Repository) ListRevisions(ctx context.Context, entityType string, entityID int) ([]model.Revision, error) {
+	revisions := []model.Revision{}
+	result := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC, id ASC").
+		Find(&revisions)
+	return revisions, result.Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"qna-api/internal/model"
+)
+
+// DefaultSearchLanguage - конфигурация текстового поиска по умолчанию
+const DefaultSearchLanguage = "english"
+
+// Фрагменты с совпадениями выделяются тегом <mark>
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+var searchLanguagePattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// SearchSchema возвращает идемпотентные DDL-запросы для полнотекстового поиска:
+// генерируемые столбцы tsvector и GIN-индексы по ним.
+// Язык задается при создании столбцов; для смены языка столбцы нужно удалить.
+func SearchSchema(language string) ([]string, error) {
+	if !searchLanguagePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid search language %q", language)
+	}
+
+	vectors := map[string]string{
+		// Заголовок получает вес A, тело - вес B
+		"questions": fmt.Sprintf(`setweight(to_tsvector('%[1]s', coalesce(title, '')), 'A') ||
+                setweight(to_tsvector('%[1]s', coalesce(text, '')), 'B')`, language),
+		"answers": fmt.Sprintf(`to_tsvector('%s', coalesce(text, ''))`, language),
+	}
+
+	statements := []string{
+		// Столбец, созданный до появления заголовков, пересоздается
+		`DO $$ BEGIN
+            IF EXISTS (SELECT 1 FROM information_schema.columns
+                WHERE table_name = 'questions' AND column_name = 'search_vector'
+                AND generation_expression NOT LIKE '%title%') THEN
+                ALTER TABLE questions DROP COLUMN search_vector;
+            END IF;
+        END $$`,
+	}
+	for _, table := range []string{"questions", "answers"} {
+		statements = append(statements,
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_vector tsvector
+            GENERATED ALWAYS AS (%s) STORED`, table, vectors[table]),
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)`, table, table),
+		)
+	}
+	return statements, nil
+}
+
+// searchKind - порядок типов результатов при равной релевантности; хранится в Cursor.Value
+var searchKind = map[string]int{
+	model.SearchTypeQuestion: 1,
+	model.SearchTypeAnswer:   0,
+}
+
+// Search ищет вопросы и ответы по запросу в синтаксисе websearch_to_tsquery
+// и возвращает их по убыванию ts_rank с подсвеченными фрагментами
+func (r *Repository) Search(ctx context.Context, opts model.SearchOptions) (*model.SearchPage, error) {
+	var sources []string
+	if opts.Type != model.SearchTypeAnswer {
+		sources = append(sources, `SELECT 'question' AS type, 1 AS kind, id, id AS question_id, title, text, created_at,
+                ts_rank(search_vector, query.q) AS rank
+            FROM questions, query
+            WHERE deleted_at IS NULL AND search_vector @@ query.q`)
+	}
+	if opts.Type != model.SearchTypeQuestion {
+		sources = append(sources, `SELECT 'answer' AS type, 0 AS kind, answers.id, question_id,
+                (SELECT title FROM questions WHERE questions.id = answers.question_id) AS title,
+                answers.text, answers.created_at,
+                ts_rank(search_vector, query.q) AS rank
+            FROM answers, query
+            WHERE deleted_at IS NULL AND search_vector @@ query.q`)
+	}
+
+	args := []interface{}{r.searchLanguage, opts.Query}
+	after := "TRUE"
+	if c := opts.After; c != nil {
+		after = "(rank, kind, id) < (?, ?, ?)"
+		args = append(args, c.Rank, c.Value, c.ID)
+	}
+	args = append(args, opts.Limit+1, r.searchLanguage, headlineOptions)
+
+	// Фрагменты строятся только для записей страницы: ts_headline дорогой
+	sql := `WITH query AS (SELECT websearch_to_tsquery(CAST(? AS regconfig), ?) AS q),
+        matches AS (` + strings.Join(sources, " UNION ALL ") + `),
+        page AS (
+            SELECT * FROM matches WHERE ` + after + `
+            ORDER BY rank DESC, kind DESC, id DESC
+            LIMIT ?
+        )
+        SELECT page.type, page.id, page.question_id, page.title, page.rank, page.created_at,
+            ts_headline(CAST(? AS regconfig), page.text, query.q, ?) AS snippet
+        FROM page, query
+        ORDER BY page.rank DESC, page.kind DESC, page.id DESC`
+
+	results := []model.SearchResult{}
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.SearchPage{Items: results}
+	if len(results) > opts.Limit {
+		page.Items = results[:opts.Limit]
+		last := page.Items[opts.Limit-1]
+		page.NextCursor = model.Cursor{
+			Sort:      model.SearchSortRank,
+			Value:     searchKind[last.Type],
+			Rank:      last.Rank,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
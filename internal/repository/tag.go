@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Подзапрос для подсчета неудаленных вопросов с тегом
+const tagQuestionCountExpr = `(SELECT COUNT(*) FROM question_tags
+    JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL
+    WHERE question_tags.tag_id = tags.id)`
+
+// Методы для тегов
+func (r *Repository) ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error) {
+	query := r.db.WithContext(ctx).Model(&model.Tag{}).
+		Select("tags.*, " + tagQuestionCountExpr + " AS question_count")
+
+	if opts.Prefix != "" {
+		query = query.Where("tags.name LIKE ?", escapeLike(opts.Prefix)+"%")
+	}
+
+	tags := []model.Tag{}
+	result := query.Order("question_count DESC, tags.name ASC").Limit(opts.Limit).Find(&tags)
+	return tags, result.Error
+}
+
+func (r *Repository) GetTagByName(ctx context.Context, name string) (*model.Tag, error) {
+	var tag model.Tag
+	result := r.db.WithContext(ctx).Where("name = ?", name).First(&tag)
+	if result.Error != nil {
+		return nil, dbError(result.Error)
+	}
+	return &tag, nil
+}
+
+// FindTags возвращает основные теги для имен и синонимов; неизвестные имена пропускаются
+func (r *Repository) FindTags(ctx context.Context, names []string) ([]model.Tag, error) {
+	tags := []model.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+	result := r.db.WithContext(ctx).Where("name IN ?", names).
+		Or("id IN (?)", r.db.Model(&model.TagSynonym{}).Select("tag_id").Where("name IN ?", names)).
+		Order("name ASC").
+		Find(&tags)
+	return tags, result.Error
+}
+
+// ResolveTags возвращает основные теги для имен, создавая недостающие
+func (r *Repository) ResolveTags(ctx context.Context, names []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		repo := &Repository{db: tx, searchLanguage: r.searchLanguage}
+
+		var synonyms []model.TagSynonym
+		if err := tx.Where("name IN ?", names).Find(&synonyms).Error; err != nil {
+			return err
+		}
+		isSynonym := make(map[string]bool, len(synonyms))
+		for _, synonym := range synonyms {
+			isSynonym[synonym.Name] = true
+		}
+
+		var missing []model.Tag
+		for _, name := range names {
+			if !isSynonym[name] {
+				missing = append(missing, model.Tag{Name: name})
+			}
+		}
+		if len(missing) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		tags, err = repo.FindTags(ctx, names)
+		return err
+	})
+	return tags, err
+}
+
+// RenameTag переименовывает тег; старое имя становится синонимом
+func (r *Repository) RenameTag(ctx context.Context, tag *model.Tag, name string) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		oldName := tag.Name
+		if err := tx.Model(tag).Update("name", name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("name = ?", name).Delete(&model.TagSynonym{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.TagSynonym{Name: oldName, TagID: tag.ID}).Error
+	})
+}
+
+// MergeTags переносит вопросы и синонимы тега source в target и удаляет source.
+// Имя source становится синонимом target.
+func (r *Repository) MergeTags(ctx context.Context, source, target *model.Tag) error {
+	return r.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO question_tags (question_id, tag_id)
+            SELECT question_id, ? FROM question_tags WHERE tag_id = ?
+            ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM question_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.TagSynonym{}).Where("tag_id = ?", source.ID).
+			Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.TagSynonym{Name: source.Name, TagID: target.ID}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"qna-api/internal/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// Методы для ролей пользователей
+
+// GetUserRole возвращает назначенную роль или nil, если роль не назначалась
+func (r *Repository) GetUserRole(ctx context.Context, userID string) (*model.UserRole, error) {
+	var roles []model.UserRole
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&roles)
+	if result.Error != nil || len(roles) == 0 {
+		return nil, result.Error
+	}
+	return &roles[0], nil
+}
+
+func (r *Repository) SetUserRole(ctx context.Context, role *model.UserRole) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by", "updated_at"}),
+	}).Create(role)
+	return dbError(result.Error)
+}
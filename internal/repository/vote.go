@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Методы для голосов
+
+// ApplyVote сохраняет голос и пересчитывает рейтинг записи в одной транзакции.
+// Нулевое значение отменяет голос. Возвращает новый рейтинг.
+func (r *Repository) ApplyVote(ctx context.Context, vote *model.Vote) (int, error) {
+	table, err := voteTable(vote.EntityType)
+	if err != nil {
+		return 0, err
+	}
+
+	var score int
+	err = r.transaction(ctx, func(tx *gorm.DB) error {
+		// Блокируем запись, чтобы голоса за нее применялись последовательно
+		var entity struct{ Score int }
+		if err := tx.Table(table).Select("score").
+			Where("id = ? AND deleted_at IS NULL", vote.EntityID).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Take(&entity).Error; err != nil {
+			return err
+		}
+
+		var votes []model.Vote
+		if err := tx.Where("entity_type = ? AND entity_id = ? AND user_id = ?",
+			vote.EntityType, vote.EntityID, vote.UserID).
+			Limit(1).Find(&votes).Error; err != nil {
+			return err
+		}
+
+		previous := 0
+		switch {
+		case len(votes) > 0 && vote.Value == 0:
+			previous = votes[0].Value
+			if err := tx.Delete(&votes[0]).Error; err != nil {
+				return err
+			}
+		case len(votes) > 0:
+			previous = votes[0].Value
+			if err := tx.Model(&votes[0]).Update("value", vote.Value).Error; err != nil {
+				return err
+			}
+		case vote.Value != 0:
+			if err := tx.Create(vote).Error; err != nil {
+				return err
+			}
+		}
+
+		delta := vote.Value - previous
+		score = entity.Score + delta
+		if delta == 0 {
+			return nil
+		}
+		return tx.Table(table).Where("id = ?", vote.EntityID).
+			UpdateColumn("score", gorm.Expr("score + ?", delta)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+func voteTable(entityType string) (string, error) {
+	switch entityType {
+	case model.VoteEntityQuestion:
+		return "questions", nil
+	case model.VoteEntityAnswer:
+		return "answers", nil
+	}
+	return "", fmt.Errorf("unknown vote entity type %q", entityType)
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qna-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Методы для вебхуков
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return dbError(r.db.WithContext(ctx).Create(webhook).Error)
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *Repository) GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error) {
+	var webhook model.Webhook
+	if err := r.db.WithContext(ctx).First(&webhook, id).Error; err != nil {
+		return nil, dbError(err)
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook удаляет вебхук вместе с его доставками
+func (r *Repository) DeleteWebhook(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&model.Webhook{}, id)
+	if result.Error != nil {
+		return dbError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries возвращает страницу доставок вебхука от новых к старым
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error) {
+	query := r.db.WithContext(ctx).Where("webhook_id = ?", webhookID)
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	if c := opts.After; c != nil {
+		query = query.Where("(created_at, id) < (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	deliveries := []model.WebhookDelivery{}
+	if err := query.Order("created_at DESC, id DESC").Limit(opts.Limit + 1).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.DeliveryPage{Items: deliveries}
+	if len(deliveries) > opts.Limit {
+		page.Items = deliveries[:opts.Limit]
+		last := page.Items[opts.Limit-1]
+		page.NextCursor = model.Cursor{
+			Sort:      model.DeliverySortNewest,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
+
+// ClaimWebhookDeliveries выбирает до limit доставок, время которых пришло, и откладывает
+// их следующую попытку на lease, чтобы другие экземпляры не отправили их повторно
+func (r *Repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	now := time.Now()
+	var ids []int
+	err := r.db.WithContext(ctx).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+        WHERE id IN (
+            SELECT id FROM webhook_deliveries
+            WHERE status = ? AND next_attempt_at <= ?
+            ORDER BY next_attempt_at, id
+            LIMIT ?
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id`, now.Add(lease), model.DeliveryPending, now, limit).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []model.WebhookDelivery
+	err = r.db.WithContext(ctx).Preload("Webhook").Order("id ASC").Find(&deliveries, ids).Error
+	return deliveries, err
+}
+
+// SaveWebhookDelivery сохраняет результат попытки доставки
+func (r *Repository) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("Status", "Attempts", "NextAttemptAt", "ResponseStatus", "LastError", "DeliveredAt").
+		Updates(delivery).Error
+}
+
+// enqueueWebhookDeliveries добавляет в outbox доставки события для подписанных вебхуков
+func enqueueWebhookDeliveries(tx *gorm.DB, event model.Event) error {
+	var webhooks []model.Webhook
+	if err := tx.Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	var deliveries []model.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       event,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: event.CreatedAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
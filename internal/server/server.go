@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"qna-api/internal/config"
+)
+
+// Server - HTTP-сервер с таймаутами соединений и плавной остановкой.
+// При остановке сервер сначала перестает быть готовым, ждет DrainDelay, чтобы балансировщик
+// перестал присылать запросы, затем дожидается текущих запросов не дольше ShutdownTimeout.
+type Server struct {
+	http            *http.Server
+	ready           atomic.Bool
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+}
+
+// New создает сервер с таймаутами из конфигурации
+func New(cfg *config.Config) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              ":" + cfg.ServerPort,
+			ReadTimeout:       cfg.ServerReadTimeout,
+			ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+			WriteTimeout:      cfg.ServerWriteTimeout,
+			IdleTimeout:       cfg.ServerIdleTimeout,
+		},
+		drainDelay:      cfg.ShutdownDrainDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// Ready сообщает, принимает ли сервер новые запросы; false до запуска и с начала остановки
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// OnShutdown регистрирует функцию, вызываемую в начале остановки: например, закрытие
+// долгоживущих потоков, которых Shutdown не дожидается
+func (s *Server) OnShutdown(fn func()) {
+	s.http.RegisterOnShutdown(fn)
+}
+
+// Run слушает адрес из конфигурации и обслуживает запросы до отмены ctx
+func (s *Server) Run(ctx context.Context, handler http.Handler) error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener, handler)
+}
+
+// Serve обслуживает запросы listener до отмены ctx, затем плавно останавливается.
+// Возвращает nil, если все запросы завершились до истечения ShutdownTimeout.
+func (s *Server) Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	s.http.Handler = handler
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.http.Serve(listener)
+	}()
+	s.ready.Store(true)
+	slog.Info("Server listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		s.ready.Store(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	s.ready.Store(false)
+	slog.Info("Shutting down: not ready, draining", "drain_delay", s.drainDelay.String())
+	time.Sleep(s.drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		// Оставшиеся соединения закрываются принудительно
+		s.http.Close()
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"qna-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(shutdownTimeout time.Duration) *Server {
+	return New(&config.Config{
+		ServerReadHeaderTimeout: time.Second,
+		ShutdownDrainDelay:      50 * time.Millisecond,
+		ShutdownTimeout:         shutdownTimeout,
+	})
+}
+
+// startServe запускает Serve в фоне и возвращает адрес и канал с результатом
+func startServe(t *testing.T, ctx context.Context, s *Server, handler http.Handler) (string, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, listener, handler) }()
+	require.Eventually(t, s.Ready, time.Second, 5*time.Millisecond)
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	s := testServer(time.Second)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServe(t, ctx, s, handler)
+
+	response := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			response <- resp
+		}
+		close(response)
+	}()
+	<-started
+
+	// Готовность снимается сразу, а текущий запрос дорабатывает
+	cancel()
+	require.Eventually(t, func() bool { return !s.Ready() }, time.Second, 5*time.Millisecond)
+	close(release)
+
+	resp, ok := <-response
+	require.True(t, ok)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "done", string(body))
+
+	assert.NoError(t, <-done)
+
+	// После остановки новые соединения не принимаются
+	_, err := http.Get(url)
+	assert.Error(t, err)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	s := testServer(50 * time.Millisecond)
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	closed := make(chan struct{})
+	s.OnShutdown(func() { close(closed) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServe(t, ctx, s, handler)
+	go http.Get(url)
+	<-started
+
+	// Запрос, не завершившийся за ShutdownTimeout, обрывается
+	cancel()
+	assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown hook was not called")
+	}
+}
@@ -1,11 +1,22 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"qna-api/internal/auth"
 	"qna-api/internal/model"
 	"time"
 )
 
-func (s *ServiceImpl) CreateAnswer(questionID int, req model.CreateAnswerRequest) (*model.Answer, error) {
+func (s *ServiceImpl) CreateAnswer(ctx context.Context, questionID int, req model.CreateAnswerRequest) (*model.Answer, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Locked {
+		return nil, ErrLocked
+	}
+
 	answer := &model.Answer{
 		QuestionID: questionID,
 		UserID:     req.UserID,
@@ -13,17 +24,102 @@ func (s *ServiceImpl) CreateAnswer(questionID int, req model.CreateAnswerRequest
 		CreatedAt:  time.Now(),
 	}
 
-	if err := s.repo.CreateAnswer(answer); err != nil {
+	if err := s.repo.CreateAnswer(ctx, answer); err != nil {
 		return nil, err
 	}
+	s.metrics.AnswerCreated()
 
 	return answer, nil
 }
 
-func (s *ServiceImpl) GetAnswer(id int) (*model.Answer, error) {
-	return s.repo.GetAnswerByID(id)
+func (s *ServiceImpl) ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	repo := s.scoped(opts.IncludeDeleted)
+
+	if _, err := repo.GetQuestionByID(ctx, questionID); err != nil {
+		return nil, err
+	}
+
+	opts.Limit = normalizeLimit(opts.Limit)
+	if opts.Sort == "" {
+		opts.Sort = model.AnswerSortOldest
+	}
+	return repo.ListAnswers(ctx, questionID, opts)
+}
+
+func (s *ServiceImpl) ListUserAnswers(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	opts.Limit = normalizeLimit(opts.Limit)
+	if opts.Sort == "" {
+		opts.Sort = model.AnswerSortOldest
+	}
+	return s.scoped(opts.IncludeDeleted).ListAnswersByUserID(ctx, userID, opts)
+}
+
+func (s *ServiceImpl) GetAnswer(ctx context.Context, id int, opts model.GetAnswerOptions) (*model.Answer, error) {
+	return s.scoped(opts.IncludeDeleted).GetAnswerByID(ctx, id)
+}
+
+func (s *ServiceImpl) UpdateAnswer(ctx context.Context, id int, req model.UpdateAnswerRequest, actor auth.Identity) (*model.Answer, error) {
+	answer, err := s.repo.GetAnswerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(actor, answer.UserID, answer.Locked); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]model.FieldChange{}
+	applyTextChange(changes, "text", &answer.Text, req.Text)
+	if len(changes) == 0 {
+		return answer, nil
+	}
+
+	answer.UpdatedAt = time.Now()
+	revision := &model.Revision{
+		EntityType: model.RevisionEntityAnswer,
+		EntityID:   answer.ID,
+		EditorID:   actor.Subject,
+		Changes:    changes,
+	}
+
+	if err := s.repo.UpdateAnswer(ctx, answer, revision); err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
+
+func (s *ServiceImpl) DeleteAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	answer, err := s.repo.GetAnswerByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(actor, answer.UserID, answer.Locked); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAnswer(ctx, id); err != nil {
+		return err
+	}
+	s.metrics.AnswerDeleted()
+	return nil
+}
+
+func (s *ServiceImpl) RestoreAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	answer, err := s.repo.Unscoped().GetAnswerByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(actor, answer.UserID, answer.Locked); err != nil {
+		return err
+	}
+	if err := s.repo.RestoreAnswer(ctx, id); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return ErrQuestionDeleted
+		}
+		return err
+	}
+	return nil
 }
 
-func (s *ServiceImpl) DeleteAnswer(id int) error {
-	return s.repo.DeleteAnswer(id)
+func (s *ServiceImpl) SetAnswerLocked(ctx context.Context, id int, locked bool) error {
+	return s.repo.SetAnswerLocked(ctx, id, locked)
 }
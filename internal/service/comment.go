@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+)
+
+// CreateQuestionComment добавляет комментарий к вопросу; к заблокированному вопросу нельзя
+func (s *ServiceImpl) CreateQuestionComment(ctx context.Context, questionID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Locked {
+		return nil, ErrLocked
+	}
+	return s.createComment(ctx, model.CommentEntityQuestion, questionID, req)
+}
+
+// CreateAnswerComment добавляет комментарий к ответу; к заблокированному ответу нельзя
+func (s *ServiceImpl) CreateAnswerComment(ctx context.Context, answerID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	answer, err := s.repo.GetAnswerByID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Locked {
+		return nil, ErrLocked
+	}
+	return s.createComment(ctx, model.CommentEntityAnswer, answerID, req)
+}
+
+// createComment сохраняет комментарий; событие о нем репозиторий записывает в той же транзакции
+func (s *ServiceImpl) createComment(ctx context.Context, entityType string, entityID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	comment := &model.Comment{
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserID:     req.UserID,
+		Text:       strings.TrimSpace(req.Text),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.repo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ListQuestionComments возвращает страницу комментариев к существующему вопросу
+func (s *ServiceImpl) ListQuestionComments(ctx context.Context, questionID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	if _, err := s.repo.GetQuestionByID(ctx, questionID); err != nil {
+		return nil, err
+	}
+	opts.Limit = normalizeLimit(opts.Limit)
+	return s.repo.ListComments(ctx, model.CommentEntityQuestion, questionID, opts)
+}
+
+// ListAnswerComments возвращает страницу комментариев к существующему ответу
+func (s *ServiceImpl) ListAnswerComments(ctx context.Context, answerID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	if _, err := s.repo.GetAnswerByID(ctx, answerID); err != nil {
+		return nil, err
+	}
+	opts.Limit = normalizeLimit(opts.Limit)
+	return s.repo.ListComments(ctx, model.CommentEntityAnswer, answerID, opts)
+}
+
+// DeleteComment удаляет комментарий; удалять может автор или модератор.
+// Событие об удалении репозиторий записывает в той же транзакции.
+func (s *ServiceImpl) DeleteComment(ctx context.Context, id int, actor auth.Identity) error {
+	comment, err := s.repo.GetCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(actor, comment.UserID, false); err != nil {
+		return err
+	}
+	return s.repo.DeleteComment(ctx, id)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"qna-api/internal/model"
+	"qna-api/internal/repository"
+)
+
+// Категории ошибок сервиса. Обработчики выбирают по ним HTTP-статус.
+var (
+	// ErrNotFound - запись не найдена
+	ErrNotFound = repository.ErrNotFound
+	// ErrConflict - изменение противоречит существующим данным
+	ErrConflict = repository.ErrConflict
+	// ErrValidation - запрос не проходит проверку
+	ErrValidation = errors.New("validation failed")
+	// ErrForbidden - пользователь не может изменять чужой контент
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Error - типизированная ошибка сервиса: категория Kind, машиночитаемый код и ошибки полей.
+// Для errors.Is ошибки с одинаковым Code равны, поэтому WithField сохраняет сравнение
+// с исходной ошибкой.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []model.FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	details := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		details[i] = field.Field + " " + field.Message
+	}
+	return e.Message + ": " + strings.Join(details, "; ")
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField возвращает копию ошибки с добавленной ошибкой поля
+func (e *Error) WithField(field, message string) *Error {
+	c := *e
+	c.Fields = append(append([]model.FieldError(nil), e.Fields...), model.FieldError{Field: field, Message: message})
+	return &c
+}
+
+var (
+	// ErrInvalidRequest - поля запроса не проходят проверку; подробности в Fields
+	ErrInvalidRequest = &Error{Kind: ErrValidation, Code: "validation_failed", Message: "request validation failed"}
+	// ErrLocked - контент заблокирован модератором
+	ErrLocked = &Error{Kind: ErrForbidden, Code: "locked", Message: "content is locked by a moderator"}
+	// ErrAnswerMismatch - ответ относится к другому вопросу
+	ErrAnswerMismatch = &Error{Kind: ErrValidation, Code: "answer_mismatch", Message: "answer does not belong to question"}
+	// ErrInvalidTag - имя тега не проходит нормализацию или тегов слишком много
+	ErrInvalidTag = &Error{Kind: ErrValidation, Code: "invalid_tag", Message: "invalid tag"}
+	// ErrTagExists - тег с таким именем уже существует
+	ErrTagExists = &Error{Kind: ErrConflict, Code: "tag_exists", Message: "tag already exists, merge the tags instead"}
+	// ErrTagNotFound - тег не найден
+	ErrTagNotFound = &Error{Kind: ErrNotFound, Code: "tag_not_found", Message: "tag not found"}
+	// ErrQuestionDeleted - вопрос удален, поэтому его ответ нельзя восстановить
+	ErrQuestionDeleted = &Error{Kind: ErrConflict, Code: "question_deleted", Message: "question is deleted, restore it first"}
+	// ErrInvalidVote - голос вне допустимых значений -1, 0, 1
+	ErrInvalidVote = &Error{Kind: ErrValidation, Code: "invalid_vote", Message: "invalid vote"}
+	// ErrInvalidWebhook - неверный адрес, секрет или список событий вебхука
+	ErrInvalidWebhook = &Error{Kind: ErrValidation, Code: "invalid_webhook", Message: "invalid webhook"}
+)
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"qna-api/internal/model"
+)
+
+func (s *ServiceImpl) PurgeDeleted(ctx context.Context, retention time.Duration) (*model.PurgeResult, error) {
+	return s.repo.PurgeDeleted(ctx, time.Now().Add(-retention))
+}
+
+// RunPurgeJob раз в interval окончательно удаляет записи старше retention,
+// пока не будет отменен ctx
+func RunPurgeJob(ctx context.Context, svc ServiceInterface, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := svc.PurgeDeleted(ctx, retention)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to purge deleted records", "error", err)
+				continue
+			}
+			if result.Questions > 0 || result.Answers > 0 {
+				slog.InfoContext(ctx, "Purged deleted records", "questions", result.Questions, "answers", result.Answers,
+					"comments", result.Comments, "votes", result.Votes)
+			}
+		}
+	}
+}
@@ -1,31 +1,234 @@
 package service
 
 import (
+	"context"
+	"qna-api/internal/auth"
 	"qna-api/internal/model"
+	"qna-api/internal/repository"
+	"strings"
 	"time"
 )
 
-func (s *ServiceImpl) GetAllQuestions() ([]model.Question, error) {
-	return s.repo.GetAllQuestions()
+func (s *ServiceImpl) ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error) {
+	opts.Limit = normalizeLimit(opts.Limit)
+	if opts.Sort == "" {
+		opts.Sort = model.QuestionSortNewest
+	}
+	if len(opts.Tags) > 0 {
+		if opts.TagMatch == "" {
+			opts.TagMatch = model.TagMatchAll
+		}
+		found, err := s.resolveFilterTags(ctx, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return &model.QuestionPage{Items: []model.Question{}}, nil
+		}
+	}
+	return s.scoped(opts.IncludeDeleted).ListQuestions(ctx, opts)
+}
+
+func (s *ServiceImpl) GetQuestion(ctx context.Context, id int, opts model.GetQuestionOptions) (*model.Question, error) {
+	repo := s.scoped(opts.IncludeDeleted)
+
+	question, err := repo.GetQuestionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.IncludeAnswers {
+		limit := normalizeLimit(opts.AnswersLimit)
+		sort := opts.AnswersSort
+		if sort == "" {
+			sort = model.AnswerSortOldest
+		}
+		page, err := repo.ListAnswers(ctx, id, model.AnswerListOptions{
+			Limit: limit,
+			Sort:  sort,
+		})
+		if err != nil {
+			return nil, err
+		}
+		question.Answers = page.Items
+
+		if question.AcceptedAnswerID != nil {
+			if question.Answers, err = acceptedFirst(ctx, repo, question.Answers, *question.AcceptedAnswerID, limit); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return question, nil
+}
+
+// acceptedFirst ставит принятый ответ в начало списка, не превышая limit
+func acceptedFirst(ctx context.Context, repo repository.RepositoryInterface, answers []model.Answer, acceptedID, limit int) ([]model.Answer, error) {
+	for i, answer := range answers {
+		if answer.ID == acceptedID {
+			result := append([]model.Answer{answer}, answers[:i]...)
+			return append(result, answers[i+1:]...), nil
+		}
+	}
+
+	// Принятый ответ не попал в первую страницу
+	accepted, err := repo.GetAnswerByID(ctx, acceptedID)
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) >= limit {
+		answers = answers[:limit-1]
+	}
+	return append([]model.Answer{*accepted}, answers...), nil
 }
 
-func (s *ServiceImpl) GetQuestion(id int) (*model.Question, error) {
-	return s.repo.GetQuestionByID(id)
+// SimilarQuestions возвращает вопросы с похожими заголовками, чтобы предупредить о дубликатах
+func (s *ServiceImpl) SimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return []model.Question{}, nil
+	}
+	if limit <= 0 || limit > maxSimilarQuestions {
+		limit = maxSimilarQuestions
+	}
+	return s.repo.FindSimilarQuestions(ctx, title, limit)
 }
 
-func (s *ServiceImpl) CreateQuestion(req model.CreateQuestionRequest) (*model.Question, error) {
+func (s *ServiceImpl) CreateQuestion(ctx context.Context, req model.CreateQuestionRequest) (*model.Question, error) {
+	tags, err := s.questionTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	question := &model.Question{
-		Text:      req.Text,
+		UserID:    req.UserID,
+		Title:     strings.TrimSpace(req.Title),
+		Slug:      model.Slugify(req.Title),
+		Body:      req.Body,
+		Tags:      tags,
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.repo.CreateQuestion(question); err != nil {
+	if err := s.repo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	s.metrics.QuestionCreated()
+
+	return question, nil
+}
+
+func (s *ServiceImpl) UpdateQuestion(ctx context.Context, id int, req model.UpdateQuestionRequest, actor auth.Identity) (*model.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(actor, question.UserID, question.Locked); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]model.FieldChange{}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		applyTextChange(changes, "title", &question.Title, &title)
+		question.Slug = model.Slugify(question.Title)
+	}
+	applyTextChange(changes, "body", &question.Body, req.Body)
+	if req.Tags != nil {
+		tags, err := s.questionTags(ctx, *req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		applyTagsChange(changes, question, tags)
+	}
+	if len(changes) == 0 {
+		return question, nil
+	}
+
+	question.UpdatedAt = time.Now()
+	revision := &model.Revision{
+		EntityType: model.RevisionEntityQuestion,
+		EntityID:   question.ID,
+		EditorID:   actor.Subject,
+		Changes:    changes,
+	}
+
+	if err := s.repo.UpdateQuestion(ctx, question, revision); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+func (s *ServiceImpl) DeleteQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	question, err := s.repo.GetQuestionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(actor, question.UserID, question.Locked); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteQuestion(ctx, id); err != nil {
+		return err
+	}
+	s.metrics.QuestionDeleted()
+	return nil
+}
+
+func (s *ServiceImpl) RestoreQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	question, err := s.repo.Unscoped().GetQuestionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(actor, question.UserID, question.Locked); err != nil {
+		return err
+	}
+	return s.repo.RestoreQuestion(ctx, id)
+}
+
+func (s *ServiceImpl) SetQuestionLocked(ctx context.Context, id int, locked bool) error {
+	return s.repo.SetQuestionLocked(ctx, id, locked)
+}
+
+// AcceptAnswer отмечает ответ принятым; это может сделать автор вопроса или модератор
+func (s *ServiceImpl) AcceptAnswer(ctx context.Context, questionID, answerID int, actor auth.Identity) (*model.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(actor, question.UserID, question.Locked); err != nil {
+		return nil, err
+	}
+
+	answer, err := s.repo.GetAnswerByID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.QuestionID != questionID {
+		return nil, ErrAnswerMismatch
+	}
+
+	if err := s.repo.SetAcceptedAnswer(ctx, questionID, &answerID); err != nil {
 		return nil, err
 	}
 
+	question.AcceptedAnswerID = &answerID
 	return question, nil
 }
 
-func (s *ServiceImpl) DeleteQuestion(id int) error {
-	return s.repo.DeleteQuestion(id)
+// UnacceptAnswer снимает отметку о принятом ответе
+func (s *ServiceImpl) UnacceptAnswer(ctx context.Context, questionID int, actor auth.Identity) (*model.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(actor, question.UserID, question.Locked); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetAcceptedAnswer(ctx, questionID, nil); err != nil {
+		return nil, err
+	}
+
+	question.AcceptedAnswerID = nil
+	return question, nil
 }
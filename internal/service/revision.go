@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"qna-api/internal/model"
+)
+
+func (s *ServiceImpl) GetQuestionRevisions(ctx context.Context, questionID int) ([]model.Revision, error) {
+	if _, err := s.repo.GetQuestionByID(ctx, questionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, model.RevisionEntityQuestion, questionID)
+}
+
+func (s *ServiceImpl) GetAnswerRevisions(ctx context.Context, answerID int) ([]model.Revision, error) {
+	if _, err := s.repo.GetAnswerByID(ctx, answerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, model.RevisionEntityAnswer, answerID)
+}
+
+// applyTextChange меняет значение поля и фиксирует правку, если оно отличается
+func applyTextChange(changes map[string]model.FieldChange, field string, current *string, next *string) {
+	if next == nil || *next == *current {
+		return
+	}
+	changes[field] = model.FieldChange{Old: *current, New: *next}
+	*current = *next
+}
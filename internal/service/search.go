@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"qna-api/internal/model"
+)
+
+func (s *ServiceImpl) Search(ctx context.Context, query string, opts model.SearchOptions) (*model.SearchPage, error) {
+	opts.Query = strings.TrimSpace(query)
+	if opts.Query == "" {
+		return &model.SearchPage{Items: []model.SearchResult{}}, nil
+	}
+
+	opts.Limit = normalizeLimit(opts.Limit)
+	return s.repo.Search(ctx, opts)
+}
@@ -1,30 +1,134 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"qna-api/internal/auth"
+	"qna-api/internal/metrics"
 	"qna-api/internal/model"
+	"qna-api/internal/policy"
 	"qna-api/internal/repository"
 )
 
 // ServiceInterface определяет контракт для сервиса
 type ServiceInterface interface {
 	// Question methods
-	GetAllQuestions() ([]model.Question, error)
-	GetQuestion(id int) (*model.Question, error)
-	CreateQuestion(req model.CreateQuestionRequest) (*model.Question, error)
-	DeleteQuestion(id int) error
+	ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error)
+	GetQuestion(ctx context.Context, id int, opts model.GetQuestionOptions) (*model.Question, error)
+	CreateQuestion(ctx context.Context, req model.CreateQuestionRequest) (*model.Question, error)
+	SimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error)
+	UpdateQuestion(ctx context.Context, id int, req model.UpdateQuestionRequest, actor auth.Identity) (*model.Question, error)
+	DeleteQuestion(ctx context.Context, id int, actor auth.Identity) error
+	RestoreQuestion(ctx context.Context, id int, actor auth.Identity) error
+	SetQuestionLocked(ctx context.Context, id int, locked bool) error
+	AcceptAnswer(ctx context.Context, questionID, answerID int, actor auth.Identity) (*model.Question, error)
+	UnacceptAnswer(ctx context.Context, questionID int, actor auth.Identity) (*model.Question, error)
 
 	// Answer methods
-	CreateAnswer(questionID int, req model.CreateAnswerRequest) (*model.Answer, error)
-	GetAnswer(id int) (*model.Answer, error)
-	DeleteAnswer(id int) error
+	CreateAnswer(ctx context.Context, questionID int, req model.CreateAnswerRequest) (*model.Answer, error)
+	ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	ListUserAnswers(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error)
+	GetAnswer(ctx context.Context, id int, opts model.GetAnswerOptions) (*model.Answer, error)
+	UpdateAnswer(ctx context.Context, id int, req model.UpdateAnswerRequest, actor auth.Identity) (*model.Answer, error)
+	DeleteAnswer(ctx context.Context, id int, actor auth.Identity) error
+	RestoreAnswer(ctx context.Context, id int, actor auth.Identity) error
+	SetAnswerLocked(ctx context.Context, id int, locked bool) error
+
+	// Comment methods
+	CreateQuestionComment(ctx context.Context, questionID int, req model.CreateCommentRequest) (*model.Comment, error)
+	CreateAnswerComment(ctx context.Context, answerID int, req model.CreateCommentRequest) (*model.Comment, error)
+	ListQuestionComments(ctx context.Context, questionID int, opts model.CommentListOptions) (*model.CommentPage, error)
+	ListAnswerComments(ctx context.Context, answerID int, opts model.CommentListOptions) (*model.CommentPage, error)
+	DeleteComment(ctx context.Context, id int, actor auth.Identity) error
+
+	// Webhook methods
+	CreateWebhook(ctx context.Context, req model.CreateWebhookRequest, actor auth.Identity) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error)
+
+	// Revision methods
+	GetQuestionRevisions(ctx context.Context, questionID int) ([]model.Revision, error)
+	GetAnswerRevisions(ctx context.Context, answerID int) ([]model.Revision, error)
+
+	// Tag methods
+	ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error)
+	RenameTag(ctx context.Context, name, newName string) (*model.Tag, error)
+	MergeTags(ctx context.Context, name, into string) (*model.Tag, error)
+	// ResolveTagNames нормализует имена тегов и заменяет синонимы основными тегами
+	ResolveTagNames(ctx context.Context, names []string) ([]string, error)
+
+	// Search ищет вопросы и ответы по тексту
+	Search(ctx context.Context, query string, opts model.SearchOptions) (*model.SearchPage, error)
+
+	// Vote methods
+	VoteQuestion(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error)
+	VoteAnswer(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error)
+
+	// User role methods
+	GetUserRole(ctx context.Context, userID string) (string, error)
+	SetUserRole(ctx context.Context, userID string, role auth.Role, actor auth.Identity) (*model.UserRole, error)
+
+	// PurgeDeleted окончательно удаляет записи, мягко удаленные дольше retention назад
+	PurgeDeleted(ctx context.Context, retention time.Duration) (*model.PurgeResult, error)
 }
 
+// maxSimilarQuestions - сколько похожих вопросов показывать при вводе заголовка
+const maxSimilarQuestions = 5
+
 // ServiceImpl - реализация сервиса
 type ServiceImpl struct {
-	repo repository.RepositoryInterface // Используем интерфейс
+	repo    repository.RepositoryInterface // Используем интерфейс
+	metrics *metrics.Metrics
+}
+
+// Option настраивает сервис
+type Option func(*ServiceImpl)
+
+// WithMetrics включает счетчики созданных и удаленных вопросов и ответов
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *ServiceImpl) {
+		s.metrics = m
+	}
 }
 
 // NewService создает новый экземпляр сервиса
-func NewService(repo repository.RepositoryInterface) ServiceInterface {
-	return &ServiceImpl{repo: repo}
+func NewService(repo repository.RepositoryInterface, opts ...Option) ServiceInterface {
+	s := &ServiceImpl{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// scoped возвращает репозиторий с учетом видимости удаленных записей
+func (s *ServiceImpl) scoped(includeDeleted bool) repository.RepositoryInterface {
+	if includeDeleted {
+		return s.repo.Unscoped()
+	}
+	return s.repo
+}
+
+// authorize проверяет, что пользователь может изменять контент автора ownerID.
+// Заблокированный контент могут изменять только модераторы.
+func authorize(actor auth.Identity, ownerID string, locked bool) error {
+	if !policy.CanModify(actor, ownerID) {
+		return ErrForbidden
+	}
+	if locked && !policy.CanModerate(actor) {
+		return ErrLocked
+	}
+	return nil
+}
+
+// normalizeLimit приводит размер страницы к допустимому диапазону
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return model.DefaultPageLimit
+	}
+	if limit > model.MaxPageLimit {
+		return model.MaxPageLimit
+	}
+	return limit
 }
@@ -1,12 +1,26 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"qna-api/internal/auth"
+	"qna-api/internal/metrics"
 	"qna-api/internal/model"
+	"qna-api/internal/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MockRepository реализует repository.RepositoryInterface
@@ -14,110 +28,348 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) GetAllQuestions() ([]model.Question, error) {
-	args := m.Called()
-	return args.Get(0).([]model.Question), args.Error(1)
+func (m *MockRepository) Unscoped() repository.RepositoryInterface {
+	m.Called()
+	return m
+}
+
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time) (*model.PurgeResult, error) {
+	args := m.Called(ctx, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PurgeResult), args.Error(1)
+}
+
+func (m *MockRepository) ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.QuestionPage), args.Error(1)
 }
 
-func (m *MockRepository) GetQuestionByID(id int) (*model.Question, error) {
-	args := m.Called(id)
+func (m *MockRepository) GetQuestionByID(ctx context.Context, id int) (*model.Question, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Question), args.Error(1)
 }
 
-func (m *MockRepository) CreateQuestion(question *model.Question) error {
-	args := m.Called(question)
+func (m *MockRepository) FindSimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error) {
+	args := m.Called(ctx, title, limit)
+	return args.Get(0).([]model.Question), args.Error(1)
+}
+
+func (m *MockRepository) CreateQuestion(ctx context.Context, question *model.Question) error {
+	args := m.Called(ctx, question)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateQuestion(ctx context.Context, question *model.Question, revision *model.Revision) error {
+	args := m.Called(ctx, question, revision)
 	return args.Error(0)
 }
 
-func (m *MockRepository) DeleteQuestion(id int) error {
-	args := m.Called(id)
+func (m *MockRepository) DeleteQuestion(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockRepository) CreateAnswer(answer *model.Answer) error {
-	args := m.Called(answer)
+func (m *MockRepository) CreateAnswer(ctx context.Context, answer *model.Answer) error {
+	args := m.Called(ctx, answer)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetAnswerByID(id int) (*model.Answer, error) {
-	args := m.Called(id)
+func (m *MockRepository) GetAnswerByID(ctx context.Context, id int) (*model.Answer, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Answer), args.Error(1)
 }
 
-func (m *MockRepository) GetAnswersByQuestionID(questionID int) ([]model.Answer, error) {
-	args := m.Called(questionID)
-	return args.Get(0).([]model.Answer), args.Error(1)
+func (m *MockRepository) ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	args := m.Called(ctx, questionID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AnswerPage), args.Error(1)
+}
+
+func (m *MockRepository) ListAnswersByUserID(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	args := m.Called(ctx, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AnswerPage), args.Error(1)
+}
+
+func (m *MockRepository) RestoreQuestion(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RestoreAnswer(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateAnswer(ctx context.Context, answer *model.Answer, revision *model.Revision) error {
+	args := m.Called(ctx, answer, revision)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteAnswer(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListRevisions(ctx context.Context, entityType string, entityID int) ([]model.Revision, error) {
+	args := m.Called(ctx, entityType, entityID)
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockRepository) SetQuestionLocked(ctx context.Context, id int, locked bool) error {
+	args := m.Called(ctx, id, locked)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetAnswerLocked(ctx context.Context, id int, locked bool) error {
+	args := m.Called(ctx, id, locked)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUserRole(ctx context.Context, userID string) (*model.UserRole, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserRole), args.Error(1)
+}
+
+func (m *MockRepository) SetUserRole(ctx context.Context, role *model.UserRole) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetAcceptedAnswer(ctx context.Context, questionID int, answerID *int) error {
+	args := m.Called(ctx, questionID, answerID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]model.Tag), args.Error(1)
+}
+
+func (m *MockRepository) GetTagByName(ctx context.Context, name string) (*model.Tag, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockRepository) FindTags(ctx context.Context, names []string) ([]model.Tag, error) {
+	args := m.Called(ctx, names)
+	return args.Get(0).([]model.Tag), args.Error(1)
+}
+
+func (m *MockRepository) ResolveTags(ctx context.Context, names []string) ([]model.Tag, error) {
+	args := m.Called(ctx, names)
+	return args.Get(0).([]model.Tag), args.Error(1)
+}
+
+func (m *MockRepository) RenameTag(ctx context.Context, tag *model.Tag, name string) error {
+	args := m.Called(ctx, tag, name)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MergeTags(ctx context.Context, source, target *model.Tag) error {
+	args := m.Called(ctx, source, target)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Search(ctx context.Context, opts model.SearchOptions) (*model.SearchPage, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SearchPage), args.Error(1)
+}
+
+func (m *MockRepository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockRepository) ListComments(ctx context.Context, entityType string, entityID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	args := m.Called(ctx, entityType, entityID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CommentPage), args.Error(1)
+}
+
+func (m *MockRepository) DeleteComment(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) DeleteWebhook(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error) {
+	args := m.Called(ctx, webhookID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DeliveryPage), args.Error(1)
+}
+
+func (m *MockRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
 }
 
-func (m *MockRepository) DeleteAnswer(id int) error {
-	args := m.Called(id)
+func (m *MockRepository) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
 	return args.Error(0)
 }
 
+func (m *MockRepository) ProcessOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) []int64) (int, error) {
+	args := m.Called(ctx, limit, publish)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) LatestEventID(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) EventsAfter(ctx context.Context, afterID int64, limit int) ([]model.OutboxEvent, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.OutboxEvent), args.Error(1)
+}
+
+func (m *MockRepository) ApplyVote(ctx context.Context, vote *model.Vote) (int, error) {
+	args := m.Called(ctx, vote)
+	return args.Int(0), args.Error(1)
+}
+
+var (
+	testUser      = auth.Identity{Subject: "user-123", Role: auth.RoleUser}
+	testModerator = auth.Identity{Subject: "moderator-1", Role: auth.RoleModerator}
+)
+
 func TestService_CreateQuestion(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	// Настраиваем mock
-	mockRepo.On("CreateQuestion", mock.AnythingOfType("*model.Question")).
+	mockRepo.On("CreateQuestion", mock.Anything, mock.AnythingOfType("*model.Question")).
 		Return(nil).
 		Run(func(args mock.Arguments) {
-			question := args.Get(0).(*model.Question)
+			question := args.Get(1).(*model.Question)
 			question.ID = 1 // Симулируем присвоение ID
 		})
 
 	// Вызываем метод service
-	req := model.CreateQuestionRequest{Text: "Test question"}
-	result, err := service.CreateQuestion(req)
+	req := model.CreateQuestionRequest{UserID: "user-123", Title: " Test question? ", Body: "Question body"}
+	result, err := service.CreateQuestion(ctx, req)
 
 	// Проверяем результат
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, "Test question", result.Text)
+	assert.Equal(t, "Test question?", result.Title)
+	assert.Equal(t, "test-question", result.Slug)
+	assert.Equal(t, "Question body", result.Body)
+	assert.Equal(t, "user-123", result.UserID)
 	assert.NotZero(t, result.ID)
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestService_GetAllQuestions(t *testing.T) {
+func TestService_ListQuestions(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
-	expectedQuestions := []model.Question{
-		{ID: 1, Text: "Question 1"},
-		{ID: 2, Text: "Question 2"},
+	expectedPage := &model.QuestionPage{
+		Items: []model.Question{
+			{ID: 1, Title: "Question 1"},
+			{ID: 2, Title: "Question 2"},
+		},
 	}
 
-	// Настраиваем mock
-	mockRepo.On("GetAllQuestions").Return(expectedQuestions, nil)
+	// Пустые параметры должны дополняться значениями по умолчанию
+	mockRepo.On("ListQuestions", mock.Anything, model.QuestionListOptions{
+		Limit: model.DefaultPageLimit,
+		Sort:  model.QuestionSortNewest,
+	}).Return(expectedPage, nil)
 
 	// Вызываем метод service
-	result, err := service.GetAllQuestions()
+	result, err := service.ListQuestions(ctx, model.QuestionListOptions{})
 
 	// Проверяем результат
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
-	assert.Equal(t, "Question 1", result[0].Text)
+	assert.Len(t, result.Items, 2)
+	assert.Equal(t, "Question 1", result.Items[0].Title)
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestService_CreateAnswer(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	// Настраиваем mock для проверки существования вопроса
-	mockRepo.On("GetQuestionByID", 1).Return(&model.Question{ID: 1, Text: "Test question"}, nil)
-	mockRepo.On("CreateAnswer", mock.AnythingOfType("*model.Answer")).
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Title: "Test question"}, nil)
+	mockRepo.On("CreateAnswer", mock.Anything, mock.AnythingOfType("*model.Answer")).
 		Return(nil).
 		Run(func(args mock.Arguments) {
-			answer := args.Get(0).(*model.Answer)
+			answer := args.Get(1).(*model.Answer)
 			answer.ID = 1 // Симулируем присвоение ID
 		})
 
@@ -126,7 +378,7 @@ func TestService_CreateAnswer(t *testing.T) {
 		UserID: "user-123",
 		Text:   "Test answer",
 	}
-	result, err := service.CreateAnswer(1, req)
+	result, err := service.CreateAnswer(ctx, 1, req)
 
 	// Проверяем результат
 	assert.NoError(t, err)
@@ -139,11 +391,12 @@ func TestService_CreateAnswer(t *testing.T) {
 }
 
 func TestService_CreateAnswer_QuestionNotFound(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	// Настраиваем mock для возврата ошибки (вопрос не найден)
-	mockRepo.On("GetQuestionByID", 999).Return(nil, assert.AnError)
+	mockRepo.On("GetQuestionByID", mock.Anything, 999).Return(nil, assert.AnError)
 	// НЕ настраиваем CreateAnswer, так как он не должен вызываться
 
 	// Вызываем метод service
@@ -151,7 +404,7 @@ func TestService_CreateAnswer_QuestionNotFound(t *testing.T) {
 		UserID: "user-123",
 		Text:   "Test answer",
 	}
-	result, err := service.CreateAnswer(999, req)
+	result, err := service.CreateAnswer(ctx, 999, req)
 
 	// Проверяем что получили ошибку
 	assert.Error(t, err)
@@ -161,6 +414,7 @@ func TestService_CreateAnswer_QuestionNotFound(t *testing.T) {
 }
 
 func TestService_GetAnswer(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
@@ -172,10 +426,10 @@ func TestService_GetAnswer(t *testing.T) {
 	}
 
 	// Настраиваем mock
-	mockRepo.On("GetAnswerByID", 1).Return(expectedAnswer, nil)
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(expectedAnswer, nil)
 
 	// Вызываем метод service
-	result, err := service.GetAnswer(1)
+	result, err := service.GetAnswer(ctx, 1, model.GetAnswerOptions{})
 
 	// Проверяем результат
 	assert.NoError(t, err)
@@ -186,15 +440,207 @@ func TestService_GetAnswer(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_GetQuestion_IncludeAnswers(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Title: "Test question"}, nil)
+	mockRepo.On("ListAnswers", mock.Anything, 1, model.AnswerListOptions{Limit: 2, Sort: model.AnswerSortOldest}).
+		Return(&model.AnswerPage{Items: []model.Answer{{ID: 1}, {ID: 2}}}, nil)
+
+	result, err := service.GetQuestion(ctx, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersLimit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Answers, 2)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetQuestion_AnswersSort(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Title: "Test question"}, nil)
+	mockRepo.On("ListAnswers", mock.Anything, 1, model.AnswerListOptions{Limit: 2, Sort: model.AnswerSortScore}).
+		Return(&model.AnswerPage{Items: []model.Answer{{ID: 2}, {ID: 1}}}, nil)
+
+	result, err := service.GetQuestion(ctx, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersLimit: 2, AnswersSort: model.AnswerSortScore})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Answers[0].ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_PassesContextToRepository(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request-1")
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	// Запросы к базе выполняются в контексте запроса, чтобы отмена клиента их прерывала
+	sameCtx := mock.MatchedBy(func(c context.Context) bool { return c.Value(key{}) == "request-1" })
+	accepted := 7
+	mockRepo.On("GetQuestionByID", sameCtx, 1).Return(&model.Question{ID: 1, AcceptedAnswerID: &accepted}, nil)
+	mockRepo.On("ListAnswers", sameCtx, 1, mock.Anything).Return(&model.AnswerPage{Items: []model.Answer{}}, nil)
+	mockRepo.On("GetAnswerByID", sameCtx, 7).Return(&model.Answer{ID: 7}, nil)
+
+	_, err := service.GetQuestion(ctx, 1, model.GetQuestionOptions{IncludeAnswers: true})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTracedService_Spans(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	mockRepo := new(MockRepository)
+	service := NewTracedService(NewService(mockRepo))
+
+	// Репозиторий получает контекст со спаном метода сервиса
+	inSpan := mock.MatchedBy(func(c context.Context) bool { return trace.SpanContextFromContext(c).IsValid() })
+	mockRepo.On("GetQuestionByID", inSpan, 1).Return(&model.Question{ID: 1}, nil)
+	mockRepo.On("GetQuestionByID", inSpan, 2).Return(nil, ErrNotFound)
+	mockRepo.On("GetAnswerByID", inSpan, 3).Return(nil, fmt.Errorf("connection reset"))
+
+	_, err := service.GetQuestion(context.Background(), 1, model.GetQuestionOptions{})
+	require.NoError(t, err)
+	_, err = service.GetQuestion(context.Background(), 2, model.GetQuestionOptions{})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = service.GetAnswer(context.Background(), 3, model.GetAnswerOptions{})
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 3)
+	assert.Equal(t, "service.GetQuestion", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int("qna.question_id", 1))
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+	// Не найденная запись - ответ 404, а не сбой
+	assert.Equal(t, codes.Unset, ended[1].Status().Code)
+	assert.Len(t, ended[1].Events(), 1)
+	assert.Equal(t, "service.GetAnswer", ended[2].Name())
+	assert.Equal(t, codes.Error, ended[2].Status().Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ListAnswers_QuestionNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 999).Return(nil, assert.AnError)
+
+	result, err := service.ListAnswers(ctx, 999, model.AnswerListOptions{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_UpdateQuestion_RecordsRevision(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Title: "Old title", Slug: "old-title", Body: "Old text"}, nil)
+	mockRepo.On("UpdateQuestion", mock.Anything, mock.AnythingOfType("*model.Question"), mock.MatchedBy(func(rev *model.Revision) bool {
+		change := rev.Changes["body"]
+		_, titleChanged := rev.Changes["title"]
+		return rev.EntityType == model.RevisionEntityQuestion &&
+			rev.EntityID == 1 &&
+			rev.EditorID == "moderator-1" &&
+			change.Old == "Old text" && change.New == "New text" &&
+			!titleChanged
+	})).Return(nil)
+
+	text := "New text"
+	result, err := service.UpdateQuestion(ctx, 1, model.UpdateQuestionRequest{Body: &text}, testModerator)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New text", result.Body)
+	assert.Equal(t, "old-title", result.Slug)
+	assert.False(t, result.UpdatedAt.IsZero())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_UpdateQuestion_TitleUpdatesSlug(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-123", Title: "Old title", Slug: "old-title"}, nil)
+	mockRepo.On("UpdateQuestion", mock.Anything, mock.MatchedBy(func(q *model.Question) bool {
+		return q.Title == "New title" && q.Slug == "new-title"
+	}), mock.MatchedBy(func(rev *model.Revision) bool {
+		return rev.Changes["title"].Old == "Old title" && rev.Changes["title"].New == "New title"
+	})).Return(nil)
+
+	title := "New title "
+	result, err := service.UpdateQuestion(ctx, 1, model.UpdateQuestionRequest{Title: &title}, testUser)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new-title", result.Slug)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_SimilarQuestions(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("FindSimilarQuestions", mock.Anything, "go modules", maxSimilarQuestions).
+		Return([]model.Question{{ID: 1}}, nil)
+
+	result, err := service.SimilarQuestions(ctx, " go modules ", 0)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	// Пустой заголовок не ищется
+	result, err = service.SimilarQuestions(ctx, "  ", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_UpdateAnswer_NoChanges(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-123", Text: "Same text"}, nil)
+
+	text := "Same text"
+	result, err := service.UpdateAnswer(ctx, 1, model.UpdateAnswerRequest{Text: &text}, testUser)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Same text", result.Text)
+
+	// Без изменений запись о правке не создается
+	mockRepo.AssertNotCalled(t, "UpdateAnswer", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_DeleteQuestion(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	// Настраиваем mock
-	mockRepo.On("DeleteQuestion", 1).Return(nil)
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1}, nil)
+	mockRepo.On("DeleteQuestion", mock.Anything, 1).Return(nil)
 
 	// Вызываем метод service
-	err := service.DeleteQuestion(1)
+	err := service.DeleteQuestion(ctx, 1, testModerator)
 
 	// Проверяем результат
 	assert.NoError(t, err)
@@ -202,18 +648,661 @@ func TestService_DeleteQuestion(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_ListQuestions_IncludeDeleted(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("Unscoped").Return()
+	mockRepo.On("ListQuestions", mock.Anything, mock.MatchedBy(func(opts model.QuestionListOptions) bool {
+		return opts.IncludeDeleted
+	})).Return(&model.QuestionPage{Items: []model.Question{}}, nil)
+
+	_, err := service.ListQuestions(ctx, model.QuestionListOptions{IncludeDeleted: true})
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_PurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	retention := 24 * time.Hour
+	mockRepo.On("PurgeDeleted", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		cutoff := time.Now().Add(-retention)
+		return before.Before(cutoff.Add(time.Minute)) && before.After(cutoff.Add(-time.Minute))
+	})).Return(&model.PurgeResult{Questions: 2, Answers: 5}, nil)
+
+	result, err := service.PurgeDeleted(ctx, retention)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Questions)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_DeleteAnswer(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepository)
 	service := NewService(mockRepo)
 
 	// Настраиваем mock
-	mockRepo.On("DeleteAnswer", 1).Return(nil)
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-123"}, nil)
+	mockRepo.On("DeleteAnswer", mock.Anything, 1).Return(nil)
 
 	// Вызываем метод service
-	err := service.DeleteAnswer(1)
+	err := service.DeleteAnswer(ctx, 1, testUser)
 
 	// Проверяем результат
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestService_RestoreAnswer_QuestionDeleted(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	// Ответ удаленного вопроса не восстанавливается
+	mockRepo.On("Unscoped").Return()
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, QuestionID: 2, UserID: "user-123"}, nil)
+	mockRepo.On("RestoreAnswer", mock.Anything, 1).Return(repository.ErrConflict)
+
+	err := service.RestoreAnswer(ctx, 1, testUser)
+
+	assert.ErrorIs(t, err, ErrQuestionDeleted)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteAnswer_NotOwner(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-123"}, nil)
+
+	err := service.DeleteAnswer(ctx, 1, auth.Identity{Subject: "intruder", Role: auth.RoleUser})
+
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	mockRepo.AssertNotCalled(t, "DeleteAnswer", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteAnswer_Moderator(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-123"}, nil)
+	mockRepo.On("DeleteAnswer", mock.Anything, 1).Return(nil)
+
+	err := service.DeleteAnswer(ctx, 1, testModerator)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteQuestion_Owner(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-123"}, nil)
+	mockRepo.On("DeleteQuestion", mock.Anything, 1).Return(nil)
+
+	err := service.DeleteQuestion(ctx, 1, testUser)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteQuestion_NotOwner(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-456"}, nil)
+
+	err := service.DeleteQuestion(ctx, 1, testUser)
+
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	mockRepo.AssertNotCalled(t, "DeleteQuestion", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_UpdateAnswer_Locked(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-123", Text: "Old text", Locked: true}, nil)
+
+	text := "New text"
+	result, err := service.UpdateAnswer(ctx, 1, model.UpdateAnswerRequest{Text: &text}, testUser)
+
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Nil(t, result)
+
+	mockRepo.AssertNotCalled(t, "UpdateAnswer", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateAnswer_QuestionLocked(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Locked: true}, nil)
+
+	result, err := service.CreateAnswer(ctx, 1, model.CreateAnswerRequest{UserID: "user-123", Text: "Test answer"})
+
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Nil(t, result)
+
+	mockRepo.AssertNotCalled(t, "CreateAnswer", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_SetUserRole(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("SetUserRole", mock.Anything, mock.MatchedBy(func(role *model.UserRole) bool {
+		return role.UserID == "user-123" && role.Role == "moderator" && role.GrantedBy == "admin-1"
+	})).Return(nil)
+
+	result, err := service.SetUserRole(ctx, "user-123", auth.RoleModerator, auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", result.Role)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ListUserAnswers(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("ListAnswersByUserID", mock.Anything, "user-123", model.AnswerListOptions{
+		Limit: model.DefaultPageLimit,
+		Sort:  model.AnswerSortOldest,
+	}).Return(&model.AnswerPage{Items: []model.Answer{{ID: 1, UserID: "user-123"}}}, nil)
+
+	result, err := service.ListUserAnswers(ctx, "user-123", model.AnswerListOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_VoteAnswer(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: "user-456"}, nil)
+	mockRepo.On("ApplyVote", mock.Anything, &model.Vote{
+		EntityType: model.VoteEntityAnswer,
+		EntityID:   1,
+		UserID:     "user-123",
+		Value:      1,
+	}).Return(5, nil)
+
+	result, err := service.VoteAnswer(ctx, 1, 1, testUser)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.Score)
+	assert.Equal(t, 1, result.Value)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_VoteQuestion_Invalid(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1}, nil)
+
+	result, err := service.VoteQuestion(ctx, 1, 2, testUser)
+
+	assert.ErrorIs(t, err, ErrInvalidVote)
+	assert.Nil(t, result)
+
+	mockRepo.AssertNotCalled(t, "ApplyVote", mock.Anything, mock.Anything)
+}
+
+func TestService_GetQuestion_AcceptedAnswerFirst(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	accepted := 5
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, AcceptedAnswerID: &accepted}, nil)
+	mockRepo.On("ListAnswers", mock.Anything, 1, model.AnswerListOptions{Limit: 2, Sort: model.AnswerSortOldest}).
+		Return(&model.AnswerPage{Items: []model.Answer{{ID: 1}, {ID: 2}}}, nil)
+	// Принятый ответ не попал в первую страницу и загружается отдельно
+	mockRepo.On("GetAnswerByID", mock.Anything, 5).Return(&model.Answer{ID: 5}, nil)
+
+	result, err := service.GetQuestion(ctx, 1, model.GetQuestionOptions{IncludeAnswers: true, AnswersLimit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Answers, 2)
+	assert.Equal(t, 5, result.Answers[0].ID)
+	assert.Equal(t, 1, result.Answers[1].ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_AcceptAnswer(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	answerID := 2
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-123"}, nil)
+	mockRepo.On("GetAnswerByID", mock.Anything, 2).Return(&model.Answer{ID: 2, QuestionID: 1}, nil)
+	mockRepo.On("SetAcceptedAnswer", mock.Anything, 1, &answerID).Return(nil)
+
+	result, err := service.AcceptAnswer(ctx, 1, 2, testUser)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *result.AcceptedAnswerID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_AcceptAnswer_NotAsker(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-456"}, nil)
+
+	_, err := service.AcceptAnswer(ctx, 1, 2, testUser)
+
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	mockRepo.AssertNotCalled(t, "SetAcceptedAnswer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AcceptAnswer_OtherQuestion(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, UserID: "user-123"}, nil)
+	mockRepo.On("GetAnswerByID", mock.Anything, 2).Return(&model.Answer{ID: 2, QuestionID: 7}, nil)
+
+	_, err := service.AcceptAnswer(ctx, 1, 2, testUser)
+
+	assert.ErrorIs(t, err, ErrAnswerMismatch)
+
+	mockRepo.AssertNotCalled(t, "SetAcceptedAnswer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Search(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("Search", mock.Anything, model.SearchOptions{Query: "postgres index", Limit: model.DefaultPageLimit}).
+		Return(&model.SearchPage{Items: []model.SearchResult{{Type: model.SearchTypeQuestion, ID: 1}}}, nil)
+
+	result, err := service.Search(ctx, "  postgres index ", model.SearchOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+
+	// Пустой запрос не обращается к базе
+	result, err = service.Search(ctx, "   ", model.SearchOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateQuestion_NormalizesTags(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	tags := []model.Tag{{ID: 1, Name: "go"}, {ID: 2, Name: "unit-testing"}}
+	mockRepo.On("ResolveTags", mock.Anything, []string{"go", "unit-testing"}).Return(tags, nil)
+	mockRepo.On("CreateQuestion", mock.Anything, mock.MatchedBy(func(q *model.Question) bool {
+		return len(q.Tags) == 2
+	})).Return(nil)
+
+	result, err := service.CreateQuestion(ctx, model.CreateQuestionRequest{
+		UserID: "user-123",
+		Title:  "Test question",
+		Body:   "Question body",
+		Tags:   []string{" Go ", "Unit  Testing", "go"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, tags, result.Tags)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateQuestion_InvalidTag(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	_, err := service.CreateQuestion(ctx, model.CreateQuestionRequest{Title: "Test", Body: "Test", Tags: []string{"c++", "<script>"}})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = service.CreateQuestion(ctx, model.CreateQuestionRequest{Title: "Test", Body: "Test", Tags: []string{"a", "b", "c", "d", "e", "f"}})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	mockRepo.AssertNotCalled(t, "CreateQuestion", mock.Anything, mock.Anything)
+}
+
+func TestService_ListQuestions_TagFilter(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	// golang - синоним go
+	mockRepo.On("FindTags", mock.Anything, []string{"golang"}).Return([]model.Tag{{ID: 1, Name: "go"}}, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"postgres"}).Return([]model.Tag{{ID: 2, Name: "postgres"}}, nil)
+	mockRepo.On("ListQuestions", mock.Anything, mock.MatchedBy(func(opts model.QuestionListOptions) bool {
+		return opts.TagMatch == model.TagMatchAll && assert.ObjectsAreEqual([]int{1, 2}, opts.TagIDs)
+	})).Return(&model.QuestionPage{Items: []model.Question{}}, nil)
+
+	_, err := service.ListQuestions(ctx, model.QuestionListOptions{Tags: []string{"Golang", "postgres"}})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ListQuestions_UnknownTag(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("FindTags", mock.Anything, []string{"go"}).Return([]model.Tag{{ID: 1, Name: "go"}}, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"cobol"}).Return([]model.Tag{}, nil)
+
+	result, err := service.ListQuestions(ctx, model.QuestionListOptions{Tags: []string{"go", "cobol"}})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+
+	mockRepo.AssertNotCalled(t, "ListQuestions", mock.Anything, mock.Anything)
+}
+
+func TestService_ResolveTagNames(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	// Синоним заменяется основным тегом, неизвестный тег остается нормализованным
+	mockRepo.On("FindTags", mock.Anything, []string{"golang", "go", "new-tag"}).Return([]model.Tag{{ID: 1, Name: "go"}}, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"golang"}).Return([]model.Tag{{ID: 1, Name: "go"}}, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"new-tag"}).Return([]model.Tag{}, nil)
+
+	names, err := service.ResolveTagNames(ctx, []string{"Golang", "go", "New Tag"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "new-tag"}, names)
+
+	_, err = service.ResolveTagNames(ctx, []string{"bad tag!"})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_UpdateQuestion_Tags(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{
+		ID:     1,
+		UserID: "user-123",
+		Tags:   []model.Tag{{ID: 1, Name: "go"}},
+	}, nil)
+	mockRepo.On("ResolveTags", mock.Anything, []string{"go", "postgres"}).
+		Return([]model.Tag{{ID: 1, Name: "go"}, {ID: 2, Name: "postgres"}}, nil)
+	mockRepo.On("UpdateQuestion", mock.Anything, mock.AnythingOfType("*model.Question"), mock.MatchedBy(func(rev *model.Revision) bool {
+		change := rev.Changes["tags"]
+		return change.Old == "go" && change.New == "go,postgres"
+	})).Return(nil)
+
+	tags := []string{"go", "postgres"}
+	result, err := service.UpdateQuestion(ctx, 1, model.UpdateQuestionRequest{Tags: &tags}, testUser)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Tags, 2)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_RenameTag_Exists(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetTagByName", mock.Anything, "golang").Return(&model.Tag{ID: 1, Name: "golang"}, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"go"}).Return([]model.Tag{{ID: 2, Name: "go"}}, nil)
+
+	_, err := service.RenameTag(ctx, "golang", "Go")
+
+	assert.ErrorIs(t, err, ErrTagExists)
+
+	mockRepo.AssertNotCalled(t, "RenameTag", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_RenameTag_ToOwnSynonym(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	tag := &model.Tag{ID: 1, Name: "golang"}
+	mockRepo.On("GetTagByName", mock.Anything, "golang").Return(tag, nil)
+	// "go" - синоним того же тега, FindTags разрешает его в сам тег
+	mockRepo.On("FindTags", mock.Anything, []string{"go"}).Return([]model.Tag{{ID: 1, Name: "golang"}}, nil)
+	mockRepo.On("RenameTag", mock.Anything, tag, "go").Return(nil)
+
+	result, err := service.RenameTag(ctx, "golang", "go")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "go", result.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_MergeTags(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	source := &model.Tag{ID: 1, Name: "golang"}
+	target := &model.Tag{ID: 2, Name: "go"}
+	mockRepo.On("GetTagByName", mock.Anything, "golang").Return(source, nil)
+	mockRepo.On("FindTags", mock.Anything, []string{"go"}).Return([]model.Tag{*target}, nil)
+	mockRepo.On("MergeTags", mock.Anything, source, target).Return(nil)
+
+	result, err := service.MergeTags(ctx, "golang", "go")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "go", result.Name)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateAnswerComment(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetAnswerByID", mock.Anything, 2).Return(&model.Answer{ID: 2}, nil)
+	mockRepo.On("CreateComment", mock.Anything, mock.MatchedBy(func(c *model.Comment) bool {
+		return c.EntityType == model.CommentEntityAnswer && c.EntityID == 2 &&
+			c.UserID == "user-123" && c.Text == "Thanks"
+	})).Return(nil)
+
+	comment, err := service.CreateAnswerComment(ctx, 2, model.CreateCommentRequest{UserID: "user-123", Text: " Thanks "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Thanks", comment.Text)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateQuestionComment_Locked(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1, Locked: true}, nil)
+
+	_, err := service.CreateQuestionComment(ctx, 1, model.CreateCommentRequest{UserID: "user-123", Text: "Hi"})
+
+	assert.ErrorIs(t, err, ErrLocked)
+	mockRepo.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+}
+
+func TestService_ListQuestionComments(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetQuestionByID", mock.Anything, 1).Return(&model.Question{ID: 1}, nil)
+	mockRepo.On("ListComments", mock.Anything, model.CommentEntityQuestion, 1, model.CommentListOptions{Limit: model.DefaultPageLimit}).
+		Return(&model.CommentPage{Items: []model.Comment{}}, nil)
+
+	_, err := service.ListQuestionComments(ctx, 1, model.CommentListOptions{})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteComment(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetCommentByID", mock.Anything, 1).Return(&model.Comment{ID: 1, UserID: "user-456"}, nil)
+	mockRepo.On("DeleteComment", mock.Anything, 1).Return(nil)
+
+	// Чужой комментарий может удалить только модератор
+	assert.ErrorIs(t, service.DeleteComment(ctx, 1, testUser), ErrForbidden)
+	assert.NoError(t, service.DeleteComment(ctx, 1, testModerator))
+
+	mockRepo.AssertNumberOfCalls(t, "DeleteComment", 1)
+}
+
+func TestService_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("CreateWebhook", mock.Anything, mock.AnythingOfType("*model.Webhook")).Return(nil)
+
+	webhook, err := service.CreateWebhook(ctx, model.CreateWebhookRequest{
+		URL:    " https://example.com/hooks ",
+		Events: []string{model.EventAnswerCreated, model.EventAnswerCreated},
+		Secret: "0123456789abcdef",
+	}, auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/hooks", webhook.URL)
+	assert.Equal(t, []string{model.EventAnswerCreated}, webhook.Events)
+	assert.Equal(t, "admin-1", webhook.CreatedBy)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateWebhook_Invalid(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	requests := []model.CreateWebhookRequest{
+		{URL: "ftp://example.com", Secret: "0123456789abcdef"},
+		{URL: "/relative", Secret: "0123456789abcdef"},
+		{URL: "https://example.com", Secret: "short"},
+		{URL: "https://example.com", Secret: "0123456789abcdef", Events: []string{"question.viewed"}},
+	}
+	for _, req := range requests {
+		_, err := service.CreateWebhook(ctx, req, testModerator)
+		assert.ErrorIs(t, err, ErrInvalidWebhook, req.URL)
+
+		var serviceErr *Error
+		if assert.ErrorAs(t, err, &serviceErr) {
+			assert.Len(t, serviceErr.Fields, 1)
+		}
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func TestService_ListWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo)
+
+	mockRepo.On("GetWebhookByID", mock.Anything, 1).Return(&model.Webhook{ID: 1}, nil)
+	mockRepo.On("GetWebhookByID", mock.Anything, 2).Return(nil, assert.AnError)
+	mockRepo.On("ListWebhookDeliveries", mock.Anything, 1, model.DeliveryListOptions{Limit: model.DefaultPageLimit}).
+		Return(&model.DeliveryPage{Items: []model.WebhookDelivery{}}, nil)
+
+	_, err := service.ListWebhookDeliveries(ctx, 1, model.DeliveryListOptions{})
+	assert.NoError(t, err)
+
+	_, err = service.ListWebhookDeliveries(ctx, 2, model.DeliveryListOptions{})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	mockRepo.AssertNumberOfCalls(t, "ListWebhookDeliveries", 1)
+}
+
+func TestError_Is(t *testing.T) {
+	err := ErrInvalidWebhook.WithField("url", "is required")
+
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.NotErrorIs(t, err, ErrInvalidTag)
+	assert.Equal(t, "invalid webhook: url is required", err.Error())
+	// WithField не меняет исходную ошибку
+	assert.Empty(t, ErrInvalidWebhook.Fields)
+
+	assert.ErrorIs(t, ErrLocked, ErrForbidden)
+	assert.ErrorIs(t, fmt.Errorf("%w: %q", ErrTagNotFound, "go"), ErrNotFound)
+	assert.ErrorIs(t, repository.ErrNotFound, ErrNotFound)
+}
+
+func TestService_CountsCreatedAndDeleted(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	appMetrics := metrics.New()
+	service := NewService(mockRepo, WithMetrics(appMetrics))
+
+	mockRepo.On("CreateQuestion", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetAnswerByID", mock.Anything, 1).Return(&model.Answer{ID: 1, UserID: testUser.Subject}, nil)
+	mockRepo.On("DeleteAnswer", mock.Anything, 1).Return(assert.AnError)
+
+	_, err := service.CreateQuestion(ctx, model.CreateQuestionRequest{UserID: testUser.Subject, Title: "Title", Body: "Body"})
+	require.NoError(t, err)
+	// Неудачное удаление не учитывается
+	require.Error(t, service.DeleteAnswer(ctx, 1, testUser))
+
+	rr := httptest.NewRecorder()
+	appMetrics.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), "qna_questions_created_total 1")
+	assert.Contains(t, rr.Body.String(), "qna_answers_deleted_total 0")
+}
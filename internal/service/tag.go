@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"qna-api/internal/model"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+#.-]*$`)
+
+func (s *ServiceImpl) ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error) {
+	opts.Limit = normalizeLimit(opts.Limit)
+	if opts.Prefix != "" {
+		prefix, err := normalizeTag(opts.Prefix)
+		if err != nil {
+			return []model.Tag{}, nil
+		}
+		opts.Prefix = prefix
+	}
+	return s.repo.ListTags(ctx, opts)
+}
+
+// RenameTag переименовывает тег; старое имя продолжает работать как синоним
+func (s *ServiceImpl) RenameTag(ctx context.Context, name, newName string) (*model.Tag, error) {
+	tag, err := s.repo.GetTagByName(ctx, strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+
+	newName, err = normalizeTag(newName)
+	if err != nil {
+		return nil, err
+	}
+	if newName == tag.Name {
+		return tag, nil
+	}
+
+	existing, err := s.repo.FindTags(ctx, []string{newName})
+	if err != nil {
+		return nil, err
+	}
+	// Собственный синоним тега занять можно: он перестанет быть синонимом
+	for _, other := range existing {
+		if other.ID != tag.ID {
+			return nil, ErrTagExists
+		}
+	}
+
+	if err := s.repo.RenameTag(ctx, tag, newName); err != nil {
+		return nil, err
+	}
+
+	tag.Name = newName
+	return tag, nil
+}
+
+// MergeTags переносит вопросы тега name в тег into и удаляет name
+func (s *ServiceImpl) MergeTags(ctx context.Context, name, into string) (*model.Tag, error) {
+	source, err := s.repo.GetTagByName(ctx, strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+
+	into, err = normalizeTag(into)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.repo.FindTags(ctx, []string{into})
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrTagNotFound, into)
+	}
+
+	target := &targets[0]
+	if target.ID == source.ID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidTag)
+	}
+
+	if err := s.repo.MergeTags(ctx, source, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// ResolveTagNames нормализует имена тегов и заменяет синонимы именами основных тегов.
+// Неизвестные имена остаются нормализованными: такой тег может появиться позже.
+func (s *ServiceImpl) ResolveTagNames(ctx context.Context, names []string) ([]string, error) {
+	names, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.repo.FindTags(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	canonical := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		canonical[tag.Name] = true
+	}
+
+	result := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		// Имя, не совпавшее с основным тегом, - синоним или неизвестный тег
+		if !canonical[name] {
+			found, err := s.repo.FindTags(ctx, []string{name})
+			if err != nil {
+				return nil, err
+			}
+			if len(found) > 0 {
+				name = found[0].Name
+			}
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// resolveFilterTags заменяет имена тегов фильтра идентификаторами основных тегов.
+// Возвращает false, если под фильтр заведомо не попадает ни один вопрос.
+func (s *ServiceImpl) resolveFilterTags(ctx context.Context, opts *model.QuestionListOptions) (bool, error) {
+	names, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return false, err
+	}
+
+	// В режиме all каждый тег должен существовать, поэтому ищем их по одному
+	groups := [][]string{names}
+	if opts.TagMatch == model.TagMatchAll {
+		groups = groups[:0]
+		for _, name := range names {
+			groups = append(groups, []string{name})
+		}
+	}
+
+	// Несколько имен могут оказаться синонимами одного тега
+	seen := map[int]bool{}
+	for _, group := range groups {
+		tags, err := s.repo.FindTags(ctx, group)
+		if err != nil {
+			return false, err
+		}
+		if len(tags) == 0 && opts.TagMatch == model.TagMatchAll {
+			return false, nil
+		}
+		for _, tag := range tags {
+			if !seen[tag.ID] {
+				seen[tag.ID] = true
+				opts.TagIDs = append(opts.TagIDs, tag.ID)
+			}
+		}
+	}
+
+	return len(opts.TagIDs) > 0, nil
+}
+
+// questionTags нормализует теги вопроса и возвращает основные теги, создавая новые
+func (s *ServiceImpl) questionTags(ctx context.Context, names []string) ([]model.Tag, error) {
+	names, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > model.MaxTagsPerQuestion {
+		return nil, fmt.Errorf("%w: at most %d tags per question", ErrInvalidTag, model.MaxTagsPerQuestion)
+	}
+	if len(names) == 0 {
+		return []model.Tag{}, nil
+	}
+	return s.repo.ResolveTags(ctx, names)
+}
+
+// applyTagsChange заменяет теги вопроса и фиксирует правку, если набор изменился
+func applyTagsChange(changes map[string]model.FieldChange, question *model.Question, tags []model.Tag) {
+	current, next := tagNames(question.Tags), tagNames(tags)
+	if current == next {
+		return
+	}
+	changes["tags"] = model.FieldChange{Old: current, New: next}
+	question.Tags = tags
+}
+
+// tagNames возвращает отсортированные имена тегов через запятую
+func tagNames(tags []model.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// normalizeTags нормализует и дедуплицирует имена тегов
+func normalizeTags(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// normalizeTag приводит имя тега к нижнему регистру и заменяет пробелы дефисами
+func normalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if len(tag) > model.MaxTagLength || !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTag, name)
+	}
+	return tag, nil
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+	"qna-api/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedService оборачивает каждый метод сервиса в спан service.<Метод>
+type tracedService struct {
+	next ServiceInterface
+}
+
+// NewTracedService добавляет трассировку к сервису; спаны запросов к базе
+// становятся дочерними спанами метода
+func NewTracedService(next ServiceInterface) ServiceInterface {
+	return &tracedService{next: next}
+}
+
+func (s *tracedService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "service."+method, trace.WithAttributes(attrs...))
+}
+
+// finish завершает спан. Ошибки запроса (не найдено, конфликт, проверка, права)
+// записываются в спан, но статусом ошибки отмечаются только сбои.
+func finish(span trace.Span, err error) {
+	defer span.End()
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) ||
+		errors.Is(err, ErrValidation) || errors.Is(err, ErrForbidden) {
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *tracedService) ListQuestions(ctx context.Context, opts model.QuestionListOptions) (*model.QuestionPage, error) {
+	ctx, span := s.start(ctx, "ListQuestions")
+	result, err := s.next.ListQuestions(ctx, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) GetQuestion(ctx context.Context, id int, opts model.GetQuestionOptions) (*model.Question, error) {
+	ctx, span := s.start(ctx, "GetQuestion", attribute.Int("qna.question_id", id))
+	result, err := s.next.GetQuestion(ctx, id, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) CreateQuestion(ctx context.Context, req model.CreateQuestionRequest) (*model.Question, error) {
+	ctx, span := s.start(ctx, "CreateQuestion")
+	result, err := s.next.CreateQuestion(ctx, req)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) SimilarQuestions(ctx context.Context, title string, limit int) ([]model.Question, error) {
+	ctx, span := s.start(ctx, "SimilarQuestions")
+	result, err := s.next.SimilarQuestions(ctx, title, limit)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) UpdateQuestion(ctx context.Context, id int, req model.UpdateQuestionRequest, actor auth.Identity) (*model.Question, error) {
+	ctx, span := s.start(ctx, "UpdateQuestion", attribute.Int("qna.question_id", id))
+	result, err := s.next.UpdateQuestion(ctx, id, req, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) DeleteQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	ctx, span := s.start(ctx, "DeleteQuestion", attribute.Int("qna.question_id", id))
+	err := s.next.DeleteQuestion(ctx, id, actor)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) RestoreQuestion(ctx context.Context, id int, actor auth.Identity) error {
+	ctx, span := s.start(ctx, "RestoreQuestion", attribute.Int("qna.question_id", id))
+	err := s.next.RestoreQuestion(ctx, id, actor)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) SetQuestionLocked(ctx context.Context, id int, locked bool) error {
+	ctx, span := s.start(ctx, "SetQuestionLocked", attribute.Int("qna.question_id", id))
+	err := s.next.SetQuestionLocked(ctx, id, locked)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) AcceptAnswer(ctx context.Context, questionID, answerID int, actor auth.Identity) (*model.Question, error) {
+	ctx, span := s.start(ctx, "AcceptAnswer", attribute.Int("qna.question_id", questionID), attribute.Int("qna.answer_id", answerID))
+	result, err := s.next.AcceptAnswer(ctx, questionID, answerID, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) UnacceptAnswer(ctx context.Context, questionID int, actor auth.Identity) (*model.Question, error) {
+	ctx, span := s.start(ctx, "UnacceptAnswer", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.UnacceptAnswer(ctx, questionID, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) CreateAnswer(ctx context.Context, questionID int, req model.CreateAnswerRequest) (*model.Answer, error) {
+	ctx, span := s.start(ctx, "CreateAnswer", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.CreateAnswer(ctx, questionID, req)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListAnswers(ctx context.Context, questionID int, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	ctx, span := s.start(ctx, "ListAnswers", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.ListAnswers(ctx, questionID, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListUserAnswers(ctx context.Context, userID string, opts model.AnswerListOptions) (*model.AnswerPage, error) {
+	ctx, span := s.start(ctx, "ListUserAnswers")
+	result, err := s.next.ListUserAnswers(ctx, userID, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) GetAnswer(ctx context.Context, id int, opts model.GetAnswerOptions) (*model.Answer, error) {
+	ctx, span := s.start(ctx, "GetAnswer", attribute.Int("qna.answer_id", id))
+	result, err := s.next.GetAnswer(ctx, id, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) UpdateAnswer(ctx context.Context, id int, req model.UpdateAnswerRequest, actor auth.Identity) (*model.Answer, error) {
+	ctx, span := s.start(ctx, "UpdateAnswer", attribute.Int("qna.answer_id", id))
+	result, err := s.next.UpdateAnswer(ctx, id, req, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) DeleteAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	ctx, span := s.start(ctx, "DeleteAnswer", attribute.Int("qna.answer_id", id))
+	err := s.next.DeleteAnswer(ctx, id, actor)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) RestoreAnswer(ctx context.Context, id int, actor auth.Identity) error {
+	ctx, span := s.start(ctx, "RestoreAnswer", attribute.Int("qna.answer_id", id))
+	err := s.next.RestoreAnswer(ctx, id, actor)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) SetAnswerLocked(ctx context.Context, id int, locked bool) error {
+	ctx, span := s.start(ctx, "SetAnswerLocked", attribute.Int("qna.answer_id", id))
+	err := s.next.SetAnswerLocked(ctx, id, locked)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) CreateQuestionComment(ctx context.Context, questionID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	ctx, span := s.start(ctx, "CreateQuestionComment", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.CreateQuestionComment(ctx, questionID, req)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) CreateAnswerComment(ctx context.Context, answerID int, req model.CreateCommentRequest) (*model.Comment, error) {
+	ctx, span := s.start(ctx, "CreateAnswerComment", attribute.Int("qna.answer_id", answerID))
+	result, err := s.next.CreateAnswerComment(ctx, answerID, req)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListQuestionComments(ctx context.Context, questionID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	ctx, span := s.start(ctx, "ListQuestionComments", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.ListQuestionComments(ctx, questionID, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListAnswerComments(ctx context.Context, answerID int, opts model.CommentListOptions) (*model.CommentPage, error) {
+	ctx, span := s.start(ctx, "ListAnswerComments", attribute.Int("qna.answer_id", answerID))
+	result, err := s.next.ListAnswerComments(ctx, answerID, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) DeleteComment(ctx context.Context, id int, actor auth.Identity) error {
+	ctx, span := s.start(ctx, "DeleteComment", attribute.Int("qna.comment_id", id))
+	err := s.next.DeleteComment(ctx, id, actor)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) CreateWebhook(ctx context.Context, req model.CreateWebhookRequest, actor auth.Identity) (*model.Webhook, error) {
+	ctx, span := s.start(ctx, "CreateWebhook")
+	result, err := s.next.CreateWebhook(ctx, req, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, span := s.start(ctx, "ListWebhooks")
+	result, err := s.next.ListWebhooks(ctx)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := s.start(ctx, "DeleteWebhook", attribute.Int("qna.webhook_id", id))
+	err := s.next.DeleteWebhook(ctx, id)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedService) ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error) {
+	ctx, span := s.start(ctx, "ListWebhookDeliveries", attribute.Int("qna.webhook_id", webhookID))
+	result, err := s.next.ListWebhookDeliveries(ctx, webhookID, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) GetQuestionRevisions(ctx context.Context, questionID int) ([]model.Revision, error) {
+	ctx, span := s.start(ctx, "GetQuestionRevisions", attribute.Int("qna.question_id", questionID))
+	result, err := s.next.GetQuestionRevisions(ctx, questionID)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) GetAnswerRevisions(ctx context.Context, answerID int) ([]model.Revision, error) {
+	ctx, span := s.start(ctx, "GetAnswerRevisions", attribute.Int("qna.answer_id", answerID))
+	result, err := s.next.GetAnswerRevisions(ctx, answerID)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ListTags(ctx context.Context, opts model.TagListOptions) ([]model.Tag, error) {
+	ctx, span := s.start(ctx, "ListTags")
+	result, err := s.next.ListTags(ctx, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) ResolveTagNames(ctx context.Context, names []string) ([]string, error) {
+	ctx, span := s.start(ctx, "ResolveTagNames")
+	result, err := s.next.ResolveTagNames(ctx, names)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) RenameTag(ctx context.Context, name, newName string) (*model.Tag, error) {
+	ctx, span := s.start(ctx, "RenameTag")
+	result, err := s.next.RenameTag(ctx, name, newName)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) MergeTags(ctx context.Context, name, into string) (*model.Tag, error) {
+	ctx, span := s.start(ctx, "MergeTags")
+	result, err := s.next.MergeTags(ctx, name, into)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) Search(ctx context.Context, query string, opts model.SearchOptions) (*model.SearchPage, error) {
+	ctx, span := s.start(ctx, "Search")
+	result, err := s.next.Search(ctx, query, opts)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) VoteQuestion(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	ctx, span := s.start(ctx, "VoteQuestion", attribute.Int("qna.question_id", id))
+	result, err := s.next.VoteQuestion(ctx, id, value, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) VoteAnswer(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	ctx, span := s.start(ctx, "VoteAnswer", attribute.Int("qna.answer_id", id))
+	result, err := s.next.VoteAnswer(ctx, id, value, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) GetUserRole(ctx context.Context, userID string) (string, error) {
+	ctx, span := s.start(ctx, "GetUserRole")
+	result, err := s.next.GetUserRole(ctx, userID)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) SetUserRole(ctx context.Context, userID string, role auth.Role, actor auth.Identity) (*model.UserRole, error) {
+	ctx, span := s.start(ctx, "SetUserRole")
+	result, err := s.next.SetUserRole(ctx, userID, role, actor)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedService) PurgeDeleted(ctx context.Context, retention time.Duration) (*model.PurgeResult, error) {
+	ctx, span := s.start(ctx, "PurgeDeleted")
+	result, err := s.next.PurgeDeleted(ctx, retention)
+	finish(span, err)
+	return result, err
+}
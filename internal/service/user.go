@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+)
+
+// GetUserRole возвращает роль, назначенную администратором, или пустую строку
+func (s *ServiceImpl) GetUserRole(ctx context.Context, userID string) (string, error) {
+	role, err := s.repo.GetUserRole(ctx, userID)
+	if err != nil || role == nil {
+		return "", err
+	}
+	return role.Role, nil
+}
+
+func (s *ServiceImpl) SetUserRole(ctx context.Context, userID string, role auth.Role, actor auth.Identity) (*model.UserRole, error) {
+	userRole := &model.UserRole{
+		UserID:    userID,
+		Role:      string(role),
+		GrantedBy: actor.Subject,
+	}
+
+	if err := s.repo.SetUserRole(ctx, userRole); err != nil {
+		return nil, err
+	}
+
+	return userRole, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+)
+
+func (s *ServiceImpl) VoteQuestion(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	question, err := s.repo.GetQuestionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if question.Locked {
+		return nil, ErrLocked
+	}
+	return s.vote(ctx, model.VoteEntityQuestion, id, value, actor)
+}
+
+func (s *ServiceImpl) VoteAnswer(ctx context.Context, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	answer, err := s.repo.GetAnswerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Locked {
+		return nil, ErrLocked
+	}
+	return s.vote(ctx, model.VoteEntityAnswer, id, value, actor)
+}
+
+// vote применяет голос пользователя; повторный голос заменяет предыдущий
+func (s *ServiceImpl) vote(ctx context.Context, entityType string, id int, value int, actor auth.Identity) (*model.VoteResult, error) {
+	if value < -1 || value > 1 {
+		return nil, ErrInvalidVote
+	}
+
+	score, err := s.repo.ApplyVote(ctx, &model.Vote{
+		EntityType: entityType,
+		EntityID:   id,
+		UserID:     actor.Subject,
+		Value:      value,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.VoteResult{Score: score, Value: value}, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"qna-api/internal/auth"
+	"qna-api/internal/model"
+)
+
+// CreateWebhook регистрирует адрес для доставки событий
+func (s *ServiceImpl) CreateWebhook(ctx context.Context, req model.CreateWebhookRequest, actor auth.Identity) (*model.Webhook, error) {
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidWebhook.WithField("url", "must be an absolute http(s) URL")
+	}
+	if len(req.Secret) < model.MinWebhookSecretLength {
+		return nil, ErrInvalidWebhook.WithField("secret", fmt.Sprintf("must be at least %d characters", model.MinWebhookSecretLength))
+	}
+
+	events := []string{}
+	for _, event := range req.Events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return nil, ErrInvalidWebhook.WithField("events", fmt.Sprintf("unknown event %q", event))
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	webhook := &model.Webhook{
+		URL:       target.String(),
+		Events:    events,
+		Secret:    req.Secret,
+		CreatedBy: actor.Subject,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *ServiceImpl) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return s.repo.ListWebhooks(ctx)
+}
+
+func (s *ServiceImpl) DeleteWebhook(ctx context.Context, id int) error {
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+// ListWebhookDeliveries возвращает историю доставок вебхука
+func (s *ServiceImpl) ListWebhookDeliveries(ctx context.Context, webhookID int, opts model.DeliveryListOptions) (*model.DeliveryPage, error) {
+	if _, err := s.repo.GetWebhookByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	opts.Limit = normalizeLimit(opts.Limit)
+	return s.repo.ListWebhookDeliveries(ctx, webhookID, opts)
+}
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey - ключ спана запроса в экземпляре gorm.DB
+const spanKey = "tracing:span"
+
+// GORMPlugin создает клиентский спан на каждый запрос GORM с текстом SQL.
+// Текст берется с плейсхолдерами, значения параметров в спан не попадают.
+type GORMPlugin struct{}
+
+func (GORMPlugin) Name() string {
+	return "tracing"
+}
+
+// registrar - колбэк GORM, привязанный к позиции в цепочке операции
+type registrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// Initialize регистрирует колбэки до и после каждой операции GORM
+func (p GORMPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	hooks := map[string][2]registrar{
+		"create": {callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		"query":  {callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		"update": {callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		"delete": {callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		"row":    {callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		"raw":    {callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for operation, hook := range hooks {
+		if err := hook[0].Register("tracing:before_"+operation, p.before(operation)); err != nil {
+			return err
+		}
+		if err := hook[1].Register("tracing:after_"+operation, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (GORMPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (GORMPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	query := db.Statement.SQL.String()
+	operation := statementOperation(query)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBQueryText(query),
+		semconv.DBOperationName(operation),
+		attribute.Int64("db.response.rows_affected", db.RowsAffected),
+	}
+	name := operation
+	if table := db.Statement.Table; table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(table))
+		name += " " + table
+	}
+	span.SetName(name)
+	span.SetAttributes(attrs...)
+
+	// Отсутствие записи - обычный ответ 404, а не сбой базы
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// statementOperation возвращает первое слово SQL (SELECT, INSERT, ...)
+func statementOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"qna-api/internal/config"
+	"qna-api/internal/logging"
+	"qna-api/internal/model"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// record подключает глобальный провайдер, который собирает завершенные спаны в память
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return spans
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestMiddleware_ContinuesTraceparent(t *testing.T) {
+	spans := record(t)
+	_, err := Setup(context.Background(), &config.Config{TracingExporter: ExporterNone})
+	require.NoError(t, err)
+
+	var handlerSpan trace.SpanContext
+	router := mux.NewRouter()
+	router.HandleFunc("/questions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+	router.Use(Middleware())
+
+	req := httptest.NewRequest("GET", "/questions/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	assert.Equal(t, "GET /questions/{id}", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, span.SpanContext(), handlerSpan)
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	attrs := attributes(span)
+	assert.Equal(t, "/questions/{id}", attrs["http.route"].AsString())
+	assert.Equal(t, int64(500), attrs["http.response.status_code"].AsInt64())
+}
+
+func TestMiddleware_AccessLogHasTrace(t *testing.T) {
+	spans := record(t)
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info")
+	require.NoError(t, err)
+
+	// Порядок как в cmd/server: спан открывается до записи лога доступа
+	router := mux.NewRouter()
+	router.HandleFunc("/questions/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+	router.Use(Middleware())
+	router.Use(logging.AccessLog(logger))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/questions/42", nil))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "Request", entry["msg"])
+	assert.Equal(t, ended[0].SpanContext().TraceID().String(), entry["trace_id"])
+	assert.Equal(t, ended[0].SpanContext().SpanID().String(), entry["span_id"])
+}
+
+func TestMiddleware_NewTraceWithoutHeader(t *testing.T) {
+	spans := record(t)
+	router := mux.NewRouter()
+	router.HandleFunc("/questions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Use(Middleware())
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/questions", nil))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.False(t, ended[0].Parent().IsValid())
+	// Ответ 4xx - ошибка клиента, а не сбой сервера
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+}
+
+func TestGORMPlugin(t *testing.T) {
+	spans := record(t)
+	// DryRun строит запросы без соединения с базой, но проходит через колбэки
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GORMPlugin{}))
+
+	ctx, parent := Tracer().Start(context.Background(), "service.GetQuestion")
+	var question model.Question
+	db.WithContext(ctx).Where("title = ?", "secret title").First(&question)
+	parent.End()
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	query := ended[0]
+	assert.Equal(t, "SELECT questions", query.Name())
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+
+	attrs := attributes(query)
+	assert.Equal(t, "postgresql", attrs["db.system.name"].AsString())
+	assert.Equal(t, "SELECT", attrs["db.operation.name"].AsString())
+	assert.Equal(t, "questions", attrs["db.collection.name"].AsString())
+	// В спан попадает SQL с плейсхолдерами, без значений параметров
+	assert.Contains(t, attrs["db.query.text"].AsString(), "title = $1")
+	assert.NotContains(t, attrs["db.query.text"].AsString(), "secret title")
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), &config.Config{TracingExporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), &config.Config{TracingExporter: ExporterFile, TracingFile: path})
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "service.ListQuestions")
+	span.End()
+	// Остановка отправляет накопленные спаны
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"service.ListQuestions"`)
+	assert.Contains(t, string(data), `"qna-api"`)
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qna-api/internal/model"
+)
+
+// Заголовки доставки
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Store - хранилище outbox доставок
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// Dispatcher отправляет доставки из outbox и повторяет неудачные с экспоненциальной задержкой
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+
+	BatchSize   int
+	MaxAttempts int           // после стольких неудач доставка помечается failed
+	BaseBackoff time.Duration // задержка после первой неудачи, дальше удваивается
+	MaxBackoff  time.Duration
+}
+
+// NewDispatcher создает диспетчер с настройками по умолчанию
+func NewDispatcher(store Store, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Dispatcher{
+		store:       store,
+		client:      client,
+		BatchSize:   50,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+	}
+}
+
+// Run раз в interval отправляет накопившиеся доставки, пока не будет отменен ctx
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchOnce(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to dispatch webhook deliveries", "error", err)
+			}
+		}
+	}
+}
+
+// saveTimeout ограничивает сохранение результата, которое выполняется и после отмены ctx
+const saveTimeout = 5 * time.Second
+
+// DispatchOnce отправляет одну пачку доставок и возвращает число попыток
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// Аренда с запасом перекрывает таймаут запроса, чтобы доставку не взял другой экземпляр
+	lease := 2*d.client.Timeout + time.Minute
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, d.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	attempts := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if ctx.Err() == nil && d.attempt(ctx, delivery) {
+			attempts++
+		} else {
+			// Остановка не считается неудачной попыткой: аренда снимается,
+			// и доставку сразу подхватит другой экземпляр
+			delivery.NextAttemptAt = time.Now()
+		}
+
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+		if err := d.store.SaveWebhookDelivery(saveCtx, delivery); err != nil {
+			slog.ErrorContext(ctx, "Failed to save webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+		cancel()
+	}
+	return attempts, nil
+}
+
+// attempt выполняет одну попытку доставки и записывает ее результат в delivery.
+// Если попытку прервала отмена ctx, delivery не меняется и возвращается false.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery) bool {
+	status, err := d.send(ctx, delivery)
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return true
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = model.DeliveryFailed
+		return true
+	}
+	delivery.NextAttemptAt = time.Now().Add(d.Backoff(delivery.Attempts))
+	return true
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	if delivery.Webhook == nil {
+		return 0, fmt.Errorf("webhook %d not found", delivery.WebhookID)
+	}
+
+	// ID события в теле - ID доставки: он не меняется между повторами и подходит для дедупликации
+	event := delivery.Payload
+	event.ID = uint64(delivery.ID)
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "qna-api-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff возвращает задержку перед повтором после attempt неудачных попыток
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempt && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
+
+// Sign вычисляет подпись доставки: HMAC-SHA256 от "<timestamp>.<body>" в виде "sha256=<hex>"
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
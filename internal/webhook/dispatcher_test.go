@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"qna-api/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef"
+
+// memoryStore - outbox в памяти: отдает pending-доставки, время которых пришло
+type memoryStore struct {
+	mu         sync.Mutex
+	deliveries []model.WebhookDelivery
+}
+
+func (s *memoryStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var claimed []model.WebhookDelivery
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.Status != model.DeliveryPending || d.NextAttemptAt.After(now) || len(claimed) == limit {
+			continue
+		}
+		d.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *d)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) SaveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == delivery.ID {
+			s.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) get(id int) model.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return model.WebhookDelivery{}
+}
+
+func newDelivery(id int, url string) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:            id,
+		WebhookID:     1,
+		EventType:     model.EventAnswerCreated,
+		Payload:       model.Event{Type: model.EventAnswerCreated, QuestionID: 1, AnswerID: 2},
+		Status:        model.DeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+		Webhook:       &model.Webhook{ID: 1, URL: url, Secret: testSecret},
+	}
+}
+
+func TestDispatchOnce_SignedDelivery(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store := &memoryStore{deliveries: []model.WebhookDelivery{newDelivery(5, receiver.URL)}}
+	d := NewDispatcher(store, receiver.Client())
+
+	n, err := d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NotNil(t, received)
+	assert.Equal(t, model.EventAnswerCreated, received.Header.Get(HeaderEvent))
+	assert.Equal(t, "5", received.Header.Get(HeaderDelivery))
+
+	// Получатель проверяет подпись тем же секретом
+	timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign(testSecret, timestamp, body), received.Header.Get(HeaderSignature))
+	assert.NotEqual(t, Sign("another-secret-value", timestamp, body), received.Header.Get(HeaderSignature))
+
+	var event model.Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, uint64(5), event.ID)
+	assert.Equal(t, 2, event.AnswerID)
+
+	delivery := store.get(5)
+	assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// Доставленное повторно не отправляется
+	n, _ = d.DispatchOnce(context.Background())
+	assert.Zero(t, n)
+}
+
+func TestDispatchOnce_RetryWithBackoff(t *testing.T) {
+	failures := 1
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	store := &memoryStore{deliveries: []model.WebhookDelivery{newDelivery(1, receiver.URL)}}
+	d := NewDispatcher(store, receiver.Client())
+
+	before := time.Now()
+	d.DispatchOnce(context.Background())
+
+	delivery := store.get(1)
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "503")
+	assert.WithinDuration(t, before.Add(d.BaseBackoff), delivery.NextAttemptAt, time.Second)
+
+	// До истечения задержки доставка не повторяется
+	n, _ := d.DispatchOnce(context.Background())
+	assert.Zero(t, n)
+
+	store.deliveries[0].NextAttemptAt = time.Now().Add(-time.Second)
+	d.DispatchOnce(context.Background())
+
+	delivery = store.get(1)
+	assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+}
+
+func TestDispatchOnce_FailsAfterMaxAttempts(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	store := &memoryStore{deliveries: []model.WebhookDelivery{newDelivery(1, receiver.URL)}}
+	d := NewDispatcher(store, receiver.Client())
+	d.MaxAttempts = 3
+
+	for i := 0; i < d.MaxAttempts; i++ {
+		store.deliveries[0].NextAttemptAt = time.Now().Add(-time.Second)
+		d.DispatchOnce(context.Background())
+	}
+
+	delivery := store.get(1)
+	assert.Equal(t, model.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+
+	store.deliveries[0].NextAttemptAt = time.Now().Add(-time.Second)
+	n, _ := d.DispatchOnce(context.Background())
+	assert.Zero(t, n)
+}
+
+func TestDispatchOnce_ShutdownReleasesDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Остановка приходит, пока запрос в полете
+		cancel()
+		<-done
+	}))
+	defer receiver.Close()
+	defer close(done)
+
+	store := &memoryStore{deliveries: []model.WebhookDelivery{
+		newDelivery(1, receiver.URL),
+		newDelivery(2, receiver.URL),
+	}}
+	d := NewDispatcher(store, receiver.Client())
+
+	n, err := d.DispatchOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	for _, id := range []int{1, 2} {
+		delivery := store.get(id)
+		assert.Equal(t, model.DeliveryPending, delivery.Status)
+		assert.Zero(t, delivery.Attempts)
+		assert.Empty(t, delivery.LastError)
+		// Аренда снята: доставку сразу заберет следующий запуск
+		assert.False(t, delivery.NextAttemptAt.After(time.Now()))
+	}
+
+	claimed, _ := store.ClaimWebhookDeliveries(context.Background(), 10, time.Minute)
+	assert.Len(t, claimed, 2)
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(&memoryStore{}, nil)
+	d.BaseBackoff = time.Second
+	d.MaxBackoff = 10 * time.Second
+
+	assert.Equal(t, time.Second, d.Backoff(1))
+	assert.Equal(t, 2*time.Second, d.Backoff(2))
+	assert.Equal(t, 8*time.Second, d.Backoff(4))
+	assert.Equal(t, 10*time.Second, d.Backoff(5))
+	assert.Equal(t, 10*time.Second, d.Backoff(50))
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+UPDATE questions SET updated_at = created_at;
+
+ALTER TABLE answers ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+UPDATE answers SET updated_at = created_at;
+
+CREATE TABLE revisions (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    editor_id VARCHAR(36) NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_revisions_entity ON revisions(entity_type, entity_id);
+
+-- История правок только дополняется
+CREATE RULE revisions_no_update AS ON UPDATE TO revisions DO INSTEAD NOTHING;
+CREATE RULE revisions_no_delete AS ON DELETE TO revisions DO INSTEAD NOTHING;
+
+-- +goose Down
+DROP TABLE revisions;
+ALTER TABLE answers DROP COLUMN updated_at;
+ALTER TABLE questions DROP COLUMN updated_at;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE answers ADD COLUMN locked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE user_roles (
+    user_id VARCHAR(36) PRIMARY KEY,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('user', 'moderator', 'admin')),
+    granted_by VARCHAR(36) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE user_roles;
+ALTER TABLE answers DROP COLUMN locked;
+ALTER TABLE questions DROP COLUMN locked;
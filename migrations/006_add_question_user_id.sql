@@ -0,0 +1,8 @@
+-- +goose Up
+-- Автор существующих вопросов неизвестен: такие вопросы могут изменять только модераторы
+ALTER TABLE questions ADD COLUMN user_id VARCHAR(36) NOT NULL DEFAULT '';
+CREATE INDEX idx_questions_user_id ON questions(user_id);
+
+-- +goose Down
+DROP INDEX idx_questions_user_id;
+ALTER TABLE questions DROP COLUMN user_id;
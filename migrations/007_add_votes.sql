@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE answers ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_questions_score ON questions(score DESC, created_at DESC, id DESC);
+CREATE INDEX idx_answers_question_score ON answers(question_id, score DESC, created_at DESC, id DESC);
+
+-- Отмененный голос удаляется, поэтому value хранит только -1 или 1
+CREATE TABLE votes (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_votes_entity_user ON votes(entity_type, entity_id, user_id);
+
+-- +goose Down
+DROP TABLE votes;
+DROP INDEX idx_answers_question_score;
+DROP INDEX idx_questions_score;
+ALTER TABLE answers DROP COLUMN score;
+ALTER TABLE questions DROP COLUMN score;
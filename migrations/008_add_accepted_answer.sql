@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN accepted_answer_id INTEGER REFERENCES answers(id) ON DELETE SET NULL;
+CREATE INDEX idx_questions_accepted_answer_id ON questions(accepted_answer_id);
+
+-- +goose Down
+DROP INDEX idx_questions_accepted_answer_id;
+ALTER TABLE questions DROP COLUMN accepted_answer_id;
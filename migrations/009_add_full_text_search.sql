@@ -0,0 +1,15 @@
+-- +goose Up
+-- Язык должен совпадать с SEARCH_LANGUAGE; cmd/migrate подставляет его автоматически
+ALTER TABLE questions ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(text, ''))) STORED;
+ALTER TABLE answers ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(text, ''))) STORED;
+
+CREATE INDEX idx_questions_search_vector ON questions USING GIN (search_vector);
+CREATE INDEX idx_answers_search_vector ON answers USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX idx_answers_search_vector;
+DROP INDEX idx_questions_search_vector;
+ALTER TABLE answers DROP COLUMN search_vector;
+ALTER TABLE questions DROP COLUMN search_vector;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE question_tags (
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (question_id, tag_id)
+);
+
+CREATE INDEX idx_question_tags_tag_id ON question_tags(tag_id);
+
+-- Синонимы заменяются основным тегом при создании вопросов и фильтрации
+CREATE TABLE tag_synonyms (
+    name VARCHAR(32) PRIMARY KEY,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tag_synonyms_tag_id ON tag_synonyms(tag_id);
+
+-- +goose Down
+DROP TABLE tag_synonyms;
+DROP TABLE question_tags;
+DROP TABLE tags;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Родитель комментария - вопрос или ответ (entity_type); внешнего ключа нет,
+-- поэтому комментарии удаляются вместе с родителем при окончательной очистке
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_comments_entity ON comments(entity_type, entity_id);
+CREATE INDEX idx_comments_user_id ON comments(user_id);
+
+-- +goose Down
+DROP TABLE comments;
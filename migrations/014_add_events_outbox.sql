@@ -0,0 +1,18 @@
+-- +goose Up
+-- Журнал доменных событий: запись добавляется в одной транзакции с изменением данных,
+-- диспетчер публикует неопубликованные события в порядке id и заполняет published_at
+CREATE TABLE events (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_events_aggregate ON events(aggregate_type, aggregate_id);
+CREATE INDEX idx_events_unpublished ON events(published_at) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE events;